@@ -57,6 +57,14 @@ func (c *serviceClient) RunScript(request *RunScriptRequest) *RunSQLResponse {
 
 }
 
+//Migrate runs pending versioned migration scripts
+func (c *serviceClient) Migrate(request *MigrateRequest) *MigrateResponse {
+	var response = &MigrateResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+migrateURI, request, response)
+	response.SetError(err)
+	return response
+}
+
 //Add table mapping
 func (c *serviceClient) AddTableMapping(request *MappingRequest) *MappingResponse {
 	var response = &MappingResponse{BaseResponse: NewBaseOkResponse()}
@@ -107,6 +115,22 @@ func (c *serviceClient) Freeze(request *FreezeRequest) *FreezeResponse {
 	return response
 }
 
+//Scaffold creates prepare, expect datasets and go test skeleton from existing database
+func (c *serviceClient) Scaffold(request *ScaffoldRequest) *ScaffoldResponse {
+	var response = &ScaffoldResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+scaffoldURI, request, response)
+	response.SetError(err)
+	return response
+}
+
+//Clone copies tables schema and data from source to dest datastore
+func (c *serviceClient) Clone(request *CloneRequest) *CloneResponse {
+	var response = &CloneResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+cloneURI, request, response)
+	response.SetError(err)
+	return response
+}
+
 //Dump creates schema from existing database
 func (c *serviceClient) Dump(request *DumpRequest) *DumpResponse {
 	var response = &DumpResponse{BaseResponse: NewBaseOkResponse()}
@@ -124,6 +148,14 @@ func (c *serviceClient) Sequence(request *SequenceRequest) *SequenceResponse {
 	return response
 }
 
+//SyncSequences sets supplied tables sequence next value to max(pk)+1
+func (c *serviceClient) SyncSequences(request *SyncSequencesRequest) *SyncSequencesResponse {
+	var response = &SyncSequencesResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+syncSequencesURI, request, response)
+	response.SetError(err)
+	return response
+}
+
 func (s *serviceClient) SetContext(context toolbox.Context) {
 
 }
@@ -136,6 +168,14 @@ func (c *serviceClient) Compare(request *CompareRequest) *CompareResponse {
 	return response
 }
 
+//CompareBatch compares many named SQL pairs concurrently
+func (c *serviceClient) CompareBatch(request *CompareBatchRequest) *CompareBatchResponse {
+	var response = &CompareBatchResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+compareBatchURI, request, response)
+	response.SetError(err)
+	return response
+}
+
 //Compare compares supplied SQLs data
 func (c *serviceClient) Ping(request *PingRequest) *PingResponse {
 	var response = &PingResponse{BaseResponse: NewBaseOkResponse()}
@@ -0,0 +1,161 @@
+package dsunit
+
+import (
+	"github.com/viant/dsc"
+	"strings"
+)
+
+func (s *service) clone(request *CloneRequest, response *CloneResponse) (err error) {
+	source := s.registry.Get(request.Datastore)
+	dest := s.registry.Get(request.DestDatastore)
+	tables := request.Tables
+	if len(tables) == 0 {
+		if tables, err = s.getTableNames(source, request.Datastore); err != nil {
+			return err
+		}
+		views, err := s.readViews(source)
+		if err != nil {
+			return err
+		}
+		tables = excludeViews(tables, views)
+	}
+	target := dest.Config().DriverName
+	if target == source.Config().DriverName {
+		target = ""
+	}
+	var schemas = make([]*TableSchema, 0, len(tables))
+	for _, table := range tables {
+		tableSchema, err := s.tableSchema(source, table, request.MappingURL, target)
+		if err != nil {
+			return err
+		}
+		schemas = append(schemas, tableSchema)
+	}
+	schemas = orderByForeignKeys(schemas)
+	if !request.SkipSchema {
+		if err = s.cloneSchema(request, response, dest, schemas); err != nil {
+			return err
+		}
+	}
+	transformer, err := newRecordTransformer(request.Transform)
+	if err != nil {
+		return err
+	}
+	connection, err := dest.ConnectionProvider().Get()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = connection.Close()
+	}()
+	adminConnection, err := s.disableForeignKeyCheck(request.DestDatastore, connection, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := s.enableForeignKeyCheck(request.DestDatastore, adminConnection); e != nil && err == nil {
+			err = e
+		}
+	}()
+	for _, table := range schemas {
+		var count int
+		count, err = s.cloneData(request, source, dest, connection, table, transformer)
+		response.Counts[table.Table] = count
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// cloneSchema creates missing dest tables with indexes and foreign keys, existing tables are dropped first if DropTables is set
+func (s *service) cloneSchema(request *CloneRequest, response *CloneResponse, dest dsc.Manager, schemas []*TableSchema) error {
+	dialect := dsc.GetDatastoreDialect(dest.Config().DriverName)
+	datastore, err := dialect.GetCurrentDatastore(dest)
+	if err != nil {
+		return err
+	}
+	existingTables, err := s.getTableNames(dest, request.DestDatastore)
+	if err != nil {
+		return err
+	}
+	var existing = make(map[string]bool)
+	for _, table := range existingTables {
+		existing[strings.ToLower(table)] = true
+	}
+	ddlDialect := getDDLDialect(dest.Config().DriverName)
+	datastore = ddlDialect.schemaName(datastore)
+	var DDLs = make([]string, 0)
+	if request.DropTables {
+		for i := len(schemas) - 1; i >= 0; i-- {
+			if table := strings.ToLower(schemas[i].Table); existing[table] {
+				DDLs = append(DDLs, ddlDialect.dropTable(datastore, schemas[i].Table))
+				delete(existing, table)
+			}
+		}
+	}
+	var created = make([]*TableSchema, 0)
+	for _, table := range schemas {
+		if !existing[strings.ToLower(table.Table)] {
+			created = append(created, table)
+		}
+	}
+	DDLs = append(DDLs, ddlDialect.schemaDDLs(datastore, nil, created, nil)...)
+	if len(DDLs) == 0 {
+		return nil
+	}
+	var SQLs = make([]string, 0, len(DDLs))
+	for _, DDL := range DDLs {
+		SQLs = append(SQLs, strings.TrimSuffix(strings.TrimSpace(DDL), ";"))
+	}
+	if _, err = dest.ExecuteAll(SQLs); err != nil {
+		return err
+	}
+	response.DDLs = append(response.DDLs, DDLs...)
+	return nil
+}
+
+// cloneData streams transformed source records into dest table with batched inserts, returns inserted record count
+func (s *service) cloneData(request *CloneRequest, source, dest dsc.Manager, connection dsc.Connection, table *TableSchema, transformer *recordTransformer) (int, error) {
+	descriptor := &dsc.TableDescriptor{Table: table.Table, Columns: table.Columns, PkColumns: table.PkColumns}
+	dmlProvider := newDatasetDmlProvider(dsc.NewDmlBuilder(descriptor))
+	var batch = make([]interface{}, 0, request.BatchSize)
+	var count = 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := connection.Begin(); err != nil {
+			return err
+		}
+		added, err := dest.PersistData(connection, batch, table.Table, nil, insertSQLProvider(dmlProvider))
+		if err != nil {
+			_ = connection.Rollback()
+			return err
+		}
+		count += added
+		batch = batch[:0]
+		return connection.Commit()
+	}
+	err := source.ReadAllWithHandler(request.TableSQL(table.Table), nil, func(scanner dsc.Scanner) (bool, error) {
+		record := make(map[string]interface{})
+		if err := scanner.Scan(record); err != nil {
+			return false, err
+		}
+		record, err := transformer.transform(record)
+		if err != nil {
+			return false, err
+		}
+		batch = append(batch, record)
+		if len(batch) >= request.BatchSize {
+			if err = flush(); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	return count, err
+}
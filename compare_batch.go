@@ -0,0 +1,67 @@
+package dsunit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// compareBatch runs named compares with concurrency limit and summarizes results
+func (s *service) compareBatch(request *CompareBatchRequest, response *CompareBatchResponse) {
+	var names = make([]string, 0, len(request.Compares))
+	for name := range request.Compares {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	response.Summaries = make([]*CompareSummary, len(names))
+	limiter := make(chan bool, request.Concurrency)
+	waitGroup := &sync.WaitGroup{}
+	waitGroup.Add(len(names))
+	for i, name := range names {
+		limiter <- true
+		go func(i int, name string) {
+			defer func() {
+				<-limiter
+				waitGroup.Done()
+			}()
+			response.Summaries[i] = newCompareSummary(name, s.Compare(request.Compares[name]))
+		}(i, name)
+	}
+	waitGroup.Wait()
+	var errored = make([]string, 0)
+	for _, summary := range response.Summaries {
+		if summary.Passed {
+			response.PassedCount++
+			continue
+		}
+		response.FailedCount++
+		if summary.Status != StatusOk {
+			errored = append(errored, summary.Name)
+		}
+	}
+	response.Passed = response.FailedCount == 0
+	if len(errored) > 0 {
+		response.SetError(fmt.Errorf("failed to run %v compare(s): %v", len(errored), strings.Join(errored, ", ")))
+	}
+}
+
+// newCompareSummary returns compare response summary
+func newCompareSummary(name string, response *CompareResponse) *CompareSummary {
+	result := &CompareSummary{
+		Name:           name,
+		Status:         response.Status,
+		Message:        response.Message,
+		Dataset1Count:  response.Dataset1Count,
+		Dataset2Count:  response.Dataset2Count,
+		MatchedRows:    response.MatchedRows,
+		MismatchedRows: response.MismatchedRows,
+		MissingRows:    response.MissingRows,
+		ExtraRows:      response.ExtraRows,
+	}
+	if response.Validation != nil {
+		result.FailedCount = response.FailedCount
+	}
+	result.Passed = result.Status == StatusOk && result.FailedCount == 0
+	return result
+}
@@ -0,0 +1,423 @@
+package dsunit
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	checksumPartitionColumn = "dsunit_partition"
+	checksumCountColumn     = "dsunit_count"
+	checksumColumn          = "dsunit_checksum"
+)
+
+// column value kinds normalized before hashing, so that vendor specific text rendering does not change row hash
+const (
+	checksumTextKind      = ""
+	checksumTimeKind      = "time"      //timestamp, datetime and date formatted as 2006-01-02 15:04:05.000000
+	checksumZonedTimeKind = "zonedTime" //timestamp with time zone converted to UTC first
+	checksumDecimalKind   = "decimal"   //numbers without trailing fraction zeros
+	checksumBoolKind      = "bool"      //true or false
+)
+
+const checksumTimeLayout = "2006-01-02 15:04:05.000000"
+
+// checksumUnsupportedTypes represents column types without portable text representation
+var checksumUnsupportedTypes = []string{"JSON", "BYTE", "BLOB", "BINARY", "ARRAY", "STRUCT", "RECORD", "GEOGRAPHY", "GEOMETRY", "INTERVAL"}
+
+// checksumDialect represents vendor row hash expressions, a row hash is the first 32 bits of MD5 of '|' joined column text, so that hashes are portable across vendors
+type checksumDialect struct {
+	text  string            //column text expression, NULL has to be converted to empty string
+	kinds map[string]string //column text expression by normalized value kind
+	hash  string            //32 bit unsigned row hash of text expression
+}
+
+const checksumBoolExpression = "CASE WHEN %[1]v IS NULL THEN '' WHEN %[1]v THEN 'true' ELSE 'false' END"
+
+var checksumDialects = map[string]*checksumDialect{
+	"mysql": {text: "COALESCE(CAST(%v AS CHAR), '')", hash: "CAST(CONV(SUBSTR(MD5(%v), 1, 8), 16, 10) AS UNSIGNED)", kinds: map[string]string{
+		checksumTimeKind:      "COALESCE(DATE_FORMAT(%v, '%%Y-%%m-%%d %%H:%%i:%%s.%%f'), '')",
+		checksumZonedTimeKind: "COALESCE(DATE_FORMAT(%v, '%%Y-%%m-%%d %%H:%%i:%%s.%%f'), '')",
+		checksumDecimalKind:   `COALESCE(REGEXP_REPLACE(CAST(%v AS CHAR), '(\\.[0-9]*[1-9])0+$|\\.0+$', '$1'), '')`,
+		checksumBoolKind:      checksumBoolExpression,
+	}},
+	"postgres": {text: "COALESCE(CAST(%v AS TEXT), '')", hash: "('x' || LPAD(SUBSTR(MD5(%v), 1, 8), 16, '0'))::BIT(64)::BIGINT", kinds: map[string]string{
+		checksumTimeKind:      "COALESCE(TO_CHAR(CAST(%v AS TIMESTAMP), 'YYYY-MM-DD HH24:MI:SS.US'), '')",
+		checksumZonedTimeKind: "COALESCE(TO_CHAR(%v AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS.US'), '')",
+		checksumDecimalKind:   `COALESCE(REGEXP_REPLACE(CAST(%v AS TEXT), '(\.[0-9]*[1-9])0+$|\.0+$', '\1'), '')`,
+		checksumBoolKind:      checksumBoolExpression,
+	}},
+	"bigquery": {text: "COALESCE(CAST(%v AS STRING), '')", hash: "CAST(CONCAT('0x', SUBSTR(TO_HEX(MD5(%v)), 1, 8)) AS INT64)", kinds: map[string]string{
+		checksumTimeKind:      "COALESCE(FORMAT_TIMESTAMP('%%Y-%%m-%%d %%H:%%M:%%E6S', CAST(%v AS TIMESTAMP)), '')",
+		checksumZonedTimeKind: "COALESCE(FORMAT_TIMESTAMP('%%Y-%%m-%%d %%H:%%M:%%E6S', CAST(%v AS TIMESTAMP), 'UTC'), '')",
+		checksumDecimalKind:   `COALESCE(REGEXP_REPLACE(CAST(%v AS STRING), r'(\.[0-9]*[1-9])0+$|\.0+$', r'\1'), '')`,
+		checksumBoolKind:      checksumBoolExpression,
+	}},
+	"vertica": {text: "COALESCE(CAST(%v AS VARCHAR), '')", hash: "HEX_TO_INTEGER(SUBSTR(MD5(%v), 1, 8))", kinds: map[string]string{
+		checksumTimeKind:      "COALESCE(TO_CHAR(CAST(%v AS TIMESTAMP), 'YYYY-MM-DD HH24:MI:SS.US'), '')",
+		checksumZonedTimeKind: "COALESCE(TO_CHAR(%v AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS.US'), '')",
+		checksumDecimalKind:   `COALESCE(REGEXP_REPLACE(CAST(%v AS VARCHAR), '(\.[0-9]*[1-9])0+$|\.0+$', '\1'), '')`,
+		checksumBoolKind:      checksumBoolExpression,
+	}},
+}
+
+// expression returns column text expression for supplied value kind
+func (d *checksumDialect) expression(kind, column string) string {
+	if expression, ok := d.kinds[kind]; ok {
+		return fmt.Sprintf(expression, column)
+	}
+	return fmt.Sprintf(d.text, column)
+}
+
+// checksumKind returns column value kind for supplied database type, types without portable text representation return an error
+func checksumKind(dbType string) (string, error) {
+	dbType = strings.ToUpper(strings.TrimSpace(dbType))
+	if index := strings.Index(dbType, "("); index != -1 {
+		dbType = strings.TrimSpace(dbType[:index])
+	}
+	if strings.HasPrefix(dbType, "_") || strings.HasSuffix(dbType, "[]") {
+		return "", fmt.Errorf("unsupported checksum column type: %v", dbType)
+	}
+	for _, unsupported := range checksumUnsupportedTypes {
+		if strings.Contains(dbType, unsupported) {
+			return "", fmt.Errorf("unsupported checksum column type: %v", dbType)
+		}
+	}
+	switch {
+	case dbType == "TIMESTAMPTZ" || strings.Contains(dbType, "WITH TIME ZONE"):
+		return checksumZonedTimeKind, nil
+	case strings.HasPrefix(dbType, "TIMESTAMP") || strings.HasPrefix(dbType, "DATETIME") || dbType == "DATE":
+		return checksumTimeKind, nil
+	case dbType == "BOOL" || dbType == "BOOLEAN":
+		return checksumBoolKind, nil
+	}
+	for _, prefix := range []string{"DECIMAL", "NUMERIC", "NUMBER", "BIGNUMERIC", "BIGDECIMAL", "FLOAT", "DOUBLE", "REAL"} {
+		if strings.HasPrefix(dbType, prefix) {
+			return checksumDecimalKind, nil
+		}
+	}
+	return checksumTextKind, nil
+}
+
+// checksumKinds returns checksum columns value kinds by column name using scanner column types
+func checksumKinds(scanner dsc.Scanner, columns []string) (map[string]string, error) {
+	names, err := scanner.Columns()
+	if err != nil {
+		return nil, err
+	}
+	types, err := scanner.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	var byName = make(map[string]string)
+	for i, name := range names {
+		if i < len(types) {
+			byName[strings.ToLower(name)] = types[i].DatabaseTypeName()
+		}
+	}
+	var result = make(map[string]string)
+	for _, column := range columns {
+		dbType, ok := byName[strings.ToLower(column)]
+		if !ok {
+			continue
+		}
+		if result[column], err = checksumKind(dbType); err != nil {
+			return nil, fmt.Errorf("%v: %v", column, err)
+		}
+	}
+	return result, nil
+}
+
+// readChecksumKinds returns checksum columns value kinds from the first source record, empty source returns no kinds
+func readChecksumKinds(manager dsc.Manager, SQL string, columns []string) (map[string]string, error) {
+	var result = make(map[string]string)
+	err := manager.ReadAllWithHandler(fmt.Sprintf("SELECT * FROM (%v) t LIMIT 1", SQL), nil, func(scanner dsc.Scanner) (bool, error) {
+		var err error
+		result, err = checksumKinds(scanner, columns)
+		return false, err
+	})
+	return result, err
+}
+
+// checksumText returns normalized value text for supplied kind, it is hashed by the portable checksum fallback
+func checksumText(kind string, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	switch kind {
+	case checksumTimeKind, checksumZonedTimeKind:
+		if timestamp, ok := value.(time.Time); ok {
+			return timestamp.UTC().Format(checksumTimeLayout)
+		}
+		if timestamp, ok := value.(*time.Time); ok && timestamp != nil {
+			return timestamp.UTC().Format(checksumTimeLayout)
+		}
+	case checksumDecimalKind:
+		text := textValue(value)
+		if strings.Contains(text, ".") {
+			text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+		}
+		return text
+	case checksumBoolKind:
+		return strconv.FormatBool(toolbox.AsBoolean(value))
+	}
+	return textValue(value)
+}
+
+// getChecksumDialect returns checksum dialect for supplied driver or nil if hash has to be computed client side
+func getChecksumDialect(driver string) *checksumDialect {
+	return checksumDialects[typeVendor(driver)]
+}
+
+// partitionChecksum represents partition row count and sum of row hashes
+type partitionChecksum struct {
+	value    interface{}
+	count    int
+	checksum int64
+}
+
+// textValue returns value text, time is formatted with timestamp literal layout, it is also used as hashed text by the portable checksum fallback
+func textValue(value interface{}) string {
+	switch actual := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(actual)
+	case time.Time:
+		return actual.Format(timestampLiteralLayout)
+	case *time.Time:
+		if actual == nil {
+			return ""
+		}
+		return actual.Format(timestampLiteralLayout)
+	case float32:
+		return strconv.FormatFloat(float64(actual), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(actual, 'f', -1, 64)
+	}
+	return toolbox.AsString(value)
+}
+
+// rowHash returns the first 32 bits of MD5 of supplied text
+func rowHash(text string) int64 {
+	sum := md5.Sum([]byte(text))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+// partitionKey returns normalized partition value key, numeric values are normalized so that 3 and 3.0 represent the same partition
+func partitionKey(value interface{}) string {
+	if value == nil {
+		return "NULL"
+	}
+	text := textValue(value)
+	if number, err := strconv.ParseFloat(text, 64); err == nil {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return text
+}
+
+// asInt64 converts aggregated value to int64
+func asInt64(value interface{}) int64 {
+	text := textValue(value)
+	if result, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return result
+	}
+	number, _ := strconv.ParseFloat(text, 64)
+	return int64(number)
+}
+
+// partitionExpression returns SQL partition expression
+func partitionExpression(checksum *CompareChecksum) string {
+	if checksum.PartitionColumn != "" {
+		return checksum.PartitionColumn
+	}
+	return fmt.Sprintf("FLOOR(%v * 1.0 / %v)", checksum.KeyColumn, checksum.PartitionSize)
+}
+
+// checksumSQL returns per partition count and checksum SQL, column text is normalized with supplied column value kinds
+func checksumSQL(dialect *checksumDialect, SQL string, checksum *CompareChecksum, kinds map[string]string) string {
+	var texts = make([]string, 0, 2*len(checksum.Columns))
+	for i, column := range checksum.Columns {
+		if i > 0 {
+			texts = append(texts, "'|'")
+		}
+		texts = append(texts, dialect.expression(kinds[column], column))
+	}
+	hash := fmt.Sprintf(dialect.hash, "CONCAT("+strings.Join(texts, ", ")+")")
+	return fmt.Sprintf("SELECT %v AS %v, COUNT(*) AS %v, SUM(%v) AS %v FROM (%v) t GROUP BY 1",
+		partitionExpression(checksum), checksumPartitionColumn, checksumCountColumn, hash, checksumColumn, SQL)
+}
+
+// partitionFilter returns SQL filtering supplied partition records
+func partitionFilter(driver, SQL string, checksum *CompareChecksum, partition *partitionChecksum) string {
+	var criteria string
+	switch {
+	case partition.value == nil && checksum.PartitionColumn != "":
+		criteria = checksum.PartitionColumn + " IS NULL"
+	case partition.value == nil:
+		criteria = checksum.KeyColumn + " IS NULL"
+	case checksum.PartitionColumn != "":
+		criteria = checksum.PartitionColumn + " = " + getDDLDialect(driver).literal(partition.value)
+	default:
+		lowerBound := asInt64(partition.value) * int64(checksum.PartitionSize)
+		criteria = fmt.Sprintf("%v >= %v AND %v < %v", checksum.KeyColumn, lowerBound, checksum.KeyColumn, lowerBound+int64(checksum.PartitionSize))
+	}
+	return fmt.Sprintf("SELECT * FROM (%v) t WHERE %v", SQL, criteria)
+}
+
+// readChecksums returns partition checksums computed by datastore or client side if vendor has no hash support
+func readChecksums(manager dsc.Manager, SQL string, checksum *CompareChecksum) (map[string]*partitionChecksum, error) {
+	var result = make(map[string]*partitionChecksum)
+	if dialect := getChecksumDialect(manager.Config().DriverName); dialect != nil {
+		kinds, err := readChecksumKinds(manager, SQL, checksum.Columns)
+		if err != nil {
+			return nil, err
+		}
+		err = manager.ReadAllWithHandler(checksumSQL(dialect, SQL, checksum, kinds), nil, func(scanner dsc.Scanner) (bool, error) {
+			record := make(map[string]interface{})
+			if err := scanner.Scan(record); err != nil {
+				return false, err
+			}
+			value := record[checksumPartitionColumn]
+			result[partitionKey(value)] = &partitionChecksum{value: value, count: int(asInt64(record[checksumCountColumn])), checksum: asInt64(record[checksumColumn])}
+			return true, nil
+		})
+		return result, err
+	}
+	var kinds map[string]string
+	err := manager.ReadAllWithHandler(SQL, nil, func(scanner dsc.Scanner) (bool, error) {
+		if kinds == nil {
+			var err error
+			if kinds, err = checksumKinds(scanner, checksum.Columns); err != nil {
+				return false, err
+			}
+		}
+		record := make(map[string]interface{})
+		if err := scanner.Scan(record); err != nil {
+			return false, err
+		}
+		var value interface{}
+		if checksum.PartitionColumn != "" {
+			value = record[checksum.PartitionColumn]
+		} else if key, ok := record[checksum.KeyColumn]; ok && key != nil {
+			number, _ := strconv.ParseFloat(textValue(key), 64)
+			value = math.Floor(number / float64(checksum.PartitionSize))
+		}
+		key := partitionKey(value)
+		partition, ok := result[key]
+		if !ok {
+			partition = &partitionChecksum{value: value}
+			result[key] = partition
+		}
+		var texts = make([]string, 0, len(checksum.Columns))
+		for _, column := range checksum.Columns {
+			texts = append(texts, checksumText(kinds[column], record[column]))
+		}
+		partition.count++
+		partition.checksum += rowHash(strings.Join(texts, "|"))
+		return true, nil
+	})
+	return result, err
+}
+
+// checksumCompare compares per partition checksums, only discrepant partitions are compared row by row
+func (s *service) checksumCompare(manager1, manager2 dsc.Manager, request *CompareRequest, response *CompareResponse, report *discrepancyReport) {
+	if err := request.Checksum.Validate(); err != nil {
+		response.SetError(err)
+		return
+	}
+	if len(request.IndexBy()) == 0 {
+		response.SetError(fmt.Errorf("checksum compare requires %v directive", assertly.IndexByDirective))
+		return
+	}
+	var checksums1, checksums2 map[string]*partitionChecksum
+	var err1, err2 error
+	waitGroup := &sync.WaitGroup{}
+	waitGroup.Add(2)
+	go func() {
+		defer waitGroup.Done()
+		checksums1, err1 = readChecksums(manager1, request.Source1.SQL, request.Checksum)
+	}()
+	go func() {
+		defer waitGroup.Done()
+		checksums2, err2 = readChecksums(manager2, request.Source2.SQL, request.Checksum)
+	}()
+	waitGroup.Wait()
+	if err1 != nil {
+		response.SetError(err1)
+		return
+	}
+	if err2 != nil {
+		response.SetError(err2)
+		return
+	}
+	var keys = make([]string, 0, len(checksums1)+len(checksums2))
+	for key, partition := range checksums1 {
+		keys = append(keys, key)
+		response.Dataset1Count += partition.count
+	}
+	for key, partition := range checksums2 {
+		if _, ok := checksums1[key]; !ok {
+			keys = append(keys, key)
+		}
+		response.Dataset2Count += partition.count
+	}
+	sort.Strings(keys)
+	response.PartitionCount = len(keys)
+	if response.Dataset1Count == 0 && response.Dataset2Count == 0 {
+		response.AddFailure(assertly.NewFailure("", "", "no data", response.Dataset1Count, response.Dataset2Count))
+		return
+	}
+	driver1, driver2 := manager1.Config().DriverName, manager2.Config().DriverName
+	discrepantRowCount := 0
+	for _, key := range keys {
+		partition1, partition2 := checksums1[key], checksums2[key]
+		if partition1 != nil && partition2 != nil && partition1.count == partition2.count && partition1.checksum == partition2.checksum {
+			response.MatchedRows += partition1.count
+			continue
+		}
+		response.DiscrepantPartitions = append(response.DiscrepantPartitions, key)
+		partition := partition1
+		if partition == nil {
+			partition = partition2
+		}
+		partitionRequest := *request
+		partitionRequest.Checksum = nil
+		partitionRequest.Source1 = &DatastoreSQL{Datastore: request.Source1.Datastore, SQL: partitionFilter(driver1, request.Source1.SQL, request.Checksum, partition)}
+		partitionRequest.Source2 = &DatastoreSQL{Datastore: request.Source2.Datastore, SQL: partitionFilter(driver2, request.Source2.SQL, request.Checksum, partition)}
+		partitionRequest.Ordered = false
+		if request.MaxRowDiscrepancy > 0 {
+			partitionRequest.MaxRowDiscrepancy = request.MaxRowDiscrepancy - discrepantRowCount
+		}
+		partitionResponse := &CompareResponse{BaseResponse: NewBaseOkResponse(), Validation: &assertly.Validation{}}
+		s.streamCompare(manager1, manager2, &partitionRequest, partitionResponse, report)
+		if partitionResponse.Status != StatusOk {
+			response.SetError(fmt.Errorf("failed to compare partition %v: %v", key, partitionResponse.Message))
+			return
+		}
+		response.MatchedRows += partitionResponse.MatchedRows
+		response.MismatchedRows += partitionResponse.MismatchedRows
+		response.MissingRows += partitionResponse.MissingRows
+		response.ExtraRows += partitionResponse.ExtraRows
+		response.PassedCount += partitionResponse.PassedCount
+		if request.MaxRowDiscrepancy == 0 || partitionRequest.MaxRowDiscrepancy > 0 {
+			for _, failure := range partitionResponse.Failures {
+				response.AddFailure(failure)
+			}
+		}
+		discrepantRowCount += partitionResponse.MismatchedRows + partitionResponse.MissingRows + partitionResponse.ExtraRows
+		if report == nil && request.MaxRowDiscrepancy > 0 && discrepantRowCount >= request.MaxRowDiscrepancy {
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRowHash(t *testing.T) {
+	assert.Equal(t, int64(0x90015098), rowHash("abc"))
+	assert.Equal(t, "2019-03-01 10:15:00", textValue(time.Date(2019, 3, 1, 10, 15, 0, 0, time.UTC)))
+	assert.Equal(t, "12.5", textValue(12.5))
+	assert.Equal(t, "", textValue(nil))
+	assert.Equal(t, "3", partitionKey([]byte("3.0")))
+	assert.Equal(t, "NULL", partitionKey(nil))
+}
+
+func TestChecksumSQL(t *testing.T) {
+	checksum := &CompareChecksum{Columns: []string{"id", "name"}, KeyColumn: "id", PartitionSize: 1000}
+	assert.Equal(t, "SELECT FLOOR(id * 1.0 / 1000) AS dsunit_partition, COUNT(*) AS dsunit_count, "+
+		"SUM(('x' || LPAD(SUBSTR(MD5(CONCAT(COALESCE(CAST(id AS TEXT), ''), '|', COALESCE(CAST(name AS TEXT), ''))), 1, 8), 16, '0'))::BIT(64)::BIGINT) AS dsunit_checksum "+
+		"FROM (SELECT * FROM users) t GROUP BY 1", checksumSQL(getChecksumDialect("pgx"), "SELECT * FROM users", checksum, nil))
+	assert.Nil(t, getChecksumDialect("sqlite3"))
+
+	assert.Equal(t, "SELECT * FROM (SELECT * FROM users) t WHERE id >= 3000 AND id < 4000",
+		partitionFilter("postgres", "SELECT * FROM users", checksum, &partitionChecksum{value: []byte("3")}))
+	byDate := &CompareChecksum{Columns: []string{"id"}, PartitionColumn: "created_date"}
+	assert.Equal(t, "SELECT * FROM (SELECT * FROM events) t WHERE created_date = '2019-03-01'",
+		partitionFilter("bigquery", "SELECT * FROM events", byDate, &partitionChecksum{value: "2019-03-01"}))
+	assert.Equal(t, "SELECT * FROM (SELECT * FROM events) t WHERE created_date IS NULL",
+		partitionFilter("bigquery", "SELECT * FROM events", byDate, &partitionChecksum{}))
+}
+
+func TestChecksumKind(t *testing.T) {
+	var useCases = []struct {
+		dbType string
+		kind   string
+		err    bool
+	}{
+		{dbType: "TIMESTAMPTZ", kind: checksumZonedTimeKind},
+		{dbType: "timestamp with time zone", kind: checksumZonedTimeKind},
+		{dbType: "TIMESTAMP", kind: checksumTimeKind},
+		{dbType: "DATETIME", kind: checksumTimeKind},
+		{dbType: "DATE", kind: checksumTimeKind},
+		{dbType: "NUMERIC(10,2)", kind: checksumDecimalKind},
+		{dbType: "BIGNUMERIC", kind: checksumDecimalKind},
+		{dbType: "FLOAT64", kind: checksumDecimalKind},
+		{dbType: "BOOL", kind: checksumBoolKind},
+		{dbType: "INT8", kind: checksumTextKind},
+		{dbType: "VARCHAR", kind: checksumTextKind},
+		{dbType: "JSONB", err: true},
+		{dbType: "BYTES", err: true},
+		{dbType: "_INT4", err: true},
+	}
+	for _, useCase := range useCases {
+		kind, err := checksumKind(useCase.dbType)
+		if useCase.err {
+			assert.NotNil(t, err, useCase.dbType)
+			continue
+		}
+		assert.Nil(t, err, useCase.dbType)
+		assert.Equal(t, useCase.kind, kind, useCase.dbType)
+	}
+}
+
+func TestChecksumText(t *testing.T) {
+	zone := time.FixedZone("PST", -8*3600)
+	assert.Equal(t, "2019-03-01 18:15:00.000000", checksumText(checksumZonedTimeKind, time.Date(2019, 3, 1, 10, 15, 0, 0, zone)))
+	assert.Equal(t, "1.5", checksumText(checksumDecimalKind, []byte("1.50")))
+	assert.Equal(t, "10", checksumText(checksumDecimalKind, []byte("10.00")))
+	assert.Equal(t, "10", checksumText(checksumDecimalKind, int64(10)))
+	assert.Equal(t, "true", checksumText(checksumBoolKind, true))
+	assert.Equal(t, "", checksumText(checksumBoolKind, nil))
+	assert.Equal(t, "abc", checksumText(checksumTextKind, "abc"))
+
+	checksum := &CompareChecksum{Columns: []string{"amount", "created", "active"}, KeyColumn: "id", PartitionSize: 10}
+	kinds := map[string]string{"amount": checksumDecimalKind, "created": checksumZonedTimeKind, "active": checksumBoolKind}
+	assert.Equal(t, "SELECT FLOOR(id * 1.0 / 10) AS dsunit_partition, COUNT(*) AS dsunit_count, "+
+		"SUM(CAST(CONCAT('0x', SUBSTR(TO_HEX(MD5(CONCAT(COALESCE(REGEXP_REPLACE(CAST(amount AS STRING), r'(\\.[0-9]*[1-9])0+$|\\.0+$', r'\\1'), ''), '|', "+
+		"COALESCE(FORMAT_TIMESTAMP('%Y-%m-%d %H:%M:%E6S', CAST(created AS TIMESTAMP), 'UTC'), ''), '|', "+
+		"CASE WHEN active IS NULL THEN '' WHEN active THEN 'true' ELSE 'false' END))), 1, 8)) AS INT64)) AS dsunit_checksum "+
+		"FROM (SELECT * FROM t) t GROUP BY 1", checksumSQL(getChecksumDialect("bigquery"), "SELECT * FROM t", checksum, kinds))
+}
+
+func TestCompareChecksum_Validate(t *testing.T) {
+	assert.NotNil(t, (&CompareChecksum{KeyColumn: "id", PartitionSize: 10}).Validate())
+	assert.NotNil(t, (&CompareChecksum{Columns: []string{"id"}}).Validate())
+	assert.NotNil(t, (&CompareChecksum{Columns: []string{"id"}, KeyColumn: "id"}).Validate())
+	assert.Nil(t, (&CompareChecksum{Columns: []string{"id"}, KeyColumn: "id", PartitionSize: 10}).Validate())
+	assert.Nil(t, (&CompareChecksum{Columns: []string{"id"}, PartitionColumn: "created_date"}).Validate())
+
+	request := &CompareRequest{Source1: &DatastoreSQL{}, Source2: &DatastoreSQL{}, Checksum: &CompareChecksum{Columns: []string{"id"}, PartitionColumn: "created_date"}}
+	assert.Nil(t, request.Validate())
+	request.ColumnMapping = map[string]string{"user_id": "id"}
+	assert.NotNil(t, request.Validate())
+	request.ColumnMapping = nil
+	request.Coercions = map[string]*ColumnCoercion{"name": {Trim: true}}
+	assert.NotNil(t, request.Validate())
+}
@@ -0,0 +1,147 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/toolbox"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// CoercionLowerCase lower case folding
+	CoercionLowerCase = "lower"
+	// CoercionUpperCase upper case folding
+	CoercionUpperCase = "upper"
+	// AllColumnsCoercion coercion key applied to columns without own coercion
+	AllColumnsCoercion = "*"
+)
+
+// ColumnCoercion represents compared column value coercion, coercions are applied to both sources in the following order: null as empty, trim, case, boolean, numeric, round
+type ColumnCoercion struct {
+	NullAsEmpty bool   `description:"converts null to empty string"`
+	Trim        bool   `description:"trims leading and trailing white spaces"`
+	Case        string `description:"case folding: lower or upper"`
+	Boolean     bool   `description:"converts 0/1, t/f, y/n, yes/no and true/false to boolean"`
+	Numeric     bool   `description:"converts numeric strings and numbers to int64 if integral, float64 otherwise"`
+	Round       *int   `description:"rounds numeric value to supplied decimal places, implies Numeric"`
+}
+
+// Validate checks if coercion is valid
+func (c *ColumnCoercion) Validate() error {
+	switch strings.ToLower(c.Case) {
+	case "", CoercionLowerCase, CoercionUpperCase:
+		return nil
+	}
+	return fmt.Errorf("unsupported coercion case: %v, supported: %v, %v", c.Case, CoercionLowerCase, CoercionUpperCase)
+}
+
+// Coerce returns coerced value
+func (c *ColumnCoercion) Coerce(value interface{}) interface{} {
+	if bytes, ok := value.([]byte); ok {
+		value = string(bytes)
+	}
+	if value == nil {
+		if c.NullAsEmpty {
+			return ""
+		}
+		return nil
+	}
+	if text, ok := value.(string); ok {
+		if c.Trim {
+			text = strings.TrimSpace(text)
+		}
+		switch strings.ToLower(c.Case) {
+		case CoercionLowerCase:
+			text = strings.ToLower(text)
+		case CoercionUpperCase:
+			text = strings.ToUpper(text)
+		}
+		value = text
+	}
+	if c.Boolean {
+		if boolValue, ok := asBoolean(value); ok {
+			return boolValue
+		}
+	}
+	if c.Numeric || c.Round != nil {
+		numeric, ok := asNumber(value)
+		if !ok {
+			return value
+		}
+		integer, isInteger := numeric.(int64)
+		if isInteger && (c.Round == nil || *c.Round >= 0) {
+			return integer
+		}
+		number, isFloat := numeric.(float64)
+		if !isFloat {
+			number = float64(integer)
+		}
+		if c.Round != nil {
+			scale := math.Pow10(*c.Round)
+			number = math.Round(number*scale) / scale
+		}
+		if number == math.Trunc(number) && math.Abs(number) < math.MaxInt64 {
+			return int64(number)
+		}
+		return number
+	}
+	return value
+}
+
+// asBoolean converts boolean like value
+func asBoolean(value interface{}) (bool, bool) {
+	if boolValue, ok := value.(bool); ok {
+		return boolValue, true
+	}
+	switch strings.ToLower(strings.TrimSpace(toolbox.AsString(value))) {
+	case "1", "t", "y", "yes", "true":
+		return true, true
+	case "0", "f", "n", "no", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// asNumber converts number or numeric string to int64 if it is an integer, float64 otherwise, so that integers above 2^53 keep precision
+func asNumber(value interface{}) (interface{}, bool) {
+	switch actual := value.(type) {
+	case bool:
+		return nil, false
+	case float64:
+		return actual, true
+	case float32:
+		return float64(actual), true
+	}
+	text := strings.TrimSpace(toolbox.AsString(value))
+	if integer, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return integer, true
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	return number, err == nil
+}
+
+// mapColumns returns record with columns renamed with supplied mapping
+func mapColumns(record map[string]interface{}, mapping map[string]string) map[string]interface{} {
+	var result = make(map[string]interface{}, len(record))
+	for column, value := range record {
+		if mapped, ok := mapping[column]; ok {
+			column = mapped
+		}
+		result[column] = value
+	}
+	return result
+}
+
+// coerceColumns applies column coercions, columns without own coercion use all columns coercion if specified
+func coerceColumns(record map[string]interface{}, coercions map[string]*ColumnCoercion) {
+	for column, value := range record {
+		coercion, ok := coercions[column]
+		if !ok {
+			if coercion, ok = coercions[AllColumnsCoercion]; !ok {
+				continue
+			}
+		}
+		record[column] = coercion.Coerce(value)
+	}
+}
@@ -0,0 +1,43 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestColumnCoercion_Coerce(t *testing.T) {
+	two := 2
+	var useCases = []struct {
+		description string
+		coercion    *ColumnCoercion
+		value       interface{}
+		expect      interface{}
+	}{
+		{"null as empty", &ColumnCoercion{NullAsEmpty: true}, nil, ""},
+		{"null", &ColumnCoercion{Trim: true}, nil, nil},
+		{"trim", &ColumnCoercion{Trim: true}, []byte(" abc\t"), "abc"},
+		{"lower", &ColumnCoercion{Trim: true, Case: "lower"}, " ABC ", "abc"},
+		{"upper", &ColumnCoercion{Case: "upper"}, "abc", "ABC"},
+		{"boolean int", &ColumnCoercion{Boolean: true}, int64(1), true},
+		{"boolean text", &ColumnCoercion{Boolean: true}, "N", false},
+		{"boolean unknown", &ColumnCoercion{Boolean: true}, "abc", "abc"},
+		{"numeric integral", &ColumnCoercion{Numeric: true}, "12400.00", int64(12400)},
+		{"numeric float", &ColumnCoercion{Numeric: true}, float32(1.5), 1.5},
+		{"numeric text", &ColumnCoercion{Numeric: true}, "abc", "abc"},
+		{"round", &ColumnCoercion{Round: &two}, 12.3456, 12.35},
+		{"round integral", &ColumnCoercion{Round: &two}, "12400.004", int64(12400)},
+		{"numeric big integer", &ColumnCoercion{Numeric: true}, "9007199254740993", int64(9007199254740993)},
+		{"numeric big int64", &ColumnCoercion{Numeric: true}, int64(9007199254740993), int64(9007199254740993)},
+		{"round big integer", &ColumnCoercion{Round: &two}, []byte("9007199254740993"), int64(9007199254740993)},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, useCase.coercion.Coerce(useCase.value), useCase.description)
+	}
+	assert.NotNil(t, (&ColumnCoercion{Case: "title"}).Validate())
+}
+
+func TestCoerceColumns(t *testing.T) {
+	record := mapColumns(map[string]interface{}{"ID": "1", "NAME": " Bob ", "username": "x"}, map[string]string{"ID": "id", "NAME": "username", "username": "NAME"})
+	coerceColumns(record, map[string]*ColumnCoercion{"username": {Trim: true}, AllColumnsCoercion: {Numeric: true}})
+	assert.Equal(t, map[string]interface{}{"id": int64(1), "username": "Bob", "NAME": "x"}, record)
+}
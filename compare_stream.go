@@ -0,0 +1,680 @@
+package dsunit
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const cursorBufferSize = 1000
+
+// recordIterator represents records stream ordered by index columns
+type recordIterator interface {
+	// Next returns next record, false if there is no more records
+	Next() (map[string]interface{}, bool, error)
+	// Close releases iterator resources
+	Close() error
+}
+
+// numericValue returns int64 or float64 value of numeric typed value, spilled json.Number is numeric too
+func numericValue(value interface{}) (interface{}, bool) {
+	switch actual := value.(type) {
+	case int:
+		return int64(actual), true
+	case int8:
+		return int64(actual), true
+	case int16:
+		return int64(actual), true
+	case int32:
+		return int64(actual), true
+	case int64:
+		return actual, true
+	case uint:
+		return numericValue(uint64(actual))
+	case uint8:
+		return int64(actual), true
+	case uint16:
+		return int64(actual), true
+	case uint32:
+		return int64(actual), true
+	case uint64:
+		if actual <= math.MaxInt64 {
+			return int64(actual), true
+		}
+		return float64(actual), true
+	case float32:
+		return numericValue(float64(actual))
+	case float64:
+		return actual, !math.IsNaN(actual)
+	case json.Number:
+		if result, err := actual.Int64(); err == nil {
+			return result, true
+		}
+		if result, err := actual.Float64(); err == nil {
+			return numericValue(result)
+		}
+	}
+	return nil, false
+}
+
+// compareNumbers compares int64 and float64 values, integers are compared natively to keep precision above 2^53
+func compareNumbers(number1, number2 interface{}) int {
+	int1, isInt1 := number1.(int64)
+	int2, isInt2 := number2.(int64)
+	if isInt1 && isInt2 {
+		switch {
+		case int1 < int2:
+			return -1
+		case int1 > int2:
+			return 1
+		}
+		return 0
+	}
+	asBig := func(number interface{}) *big.Float {
+		if value, ok := number.(int64); ok {
+			return new(big.Float).SetInt64(value)
+		}
+		return big.NewFloat(number.(float64))
+	}
+	return asBig(number1).Cmp(asBig(number2))
+}
+
+// compareValues compares values numerically if both are numeric types, lexically otherwise, so that text keys follow database ORDER BY
+func compareValues(value1, value2 interface{}) int {
+	number1, ok1 := numericValue(value1)
+	number2, ok2 := numericValue(value2)
+	if ok1 && ok2 {
+		return compareNumbers(number1, number2)
+	}
+	return strings.Compare(toolbox.AsString(value1), toolbox.AsString(value2))
+}
+
+// compareKeys compares records by index columns
+func compareKeys(indexBy []string, record1, record2 map[string]interface{}) int {
+	for _, key := range indexBy {
+		if result := compareValues(record1[key], record2[key]); result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// keyPath returns record index columns path i.e. id:1, seq:2
+func keyPath(indexBy []string, record map[string]interface{}) string {
+	var keys = make([]string, 0, len(indexBy))
+	for _, key := range indexBy {
+		keys = append(keys, key+":"+toolbox.AsString(record[key]))
+	}
+	return strings.Join(keys, ", ")
+}
+
+// Numeric column kinds used to normalize text values of numeric columns
+const (
+	integerColumnKind = "integer"
+	decimalColumnKind = "decimal"
+)
+
+// numericColumnKind returns integer or decimal kind for numeric database type, empty otherwise
+func numericColumnKind(dbType string) string {
+	dbType = strings.ToUpper(strings.TrimSpace(dbType))
+	if index := strings.Index(dbType, "("); index != -1 {
+		dbType = strings.TrimSpace(dbType[:index])
+	}
+	dbType = strings.TrimSpace(strings.Replace(dbType, "UNSIGNED", "", 1))
+	switch dbType {
+	case "INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT", "MEDIUMINT", "INT2", "INT4", "INT8", "INT64", "SERIAL", "BIGSERIAL", "SMALLSERIAL":
+		return integerColumnKind
+	case "DECIMAL", "NUMERIC", "NUMBER", "BIGNUMERIC", "BIGDECIMAL", "FLOAT", "FLOAT4", "FLOAT8", "FLOAT64", "DOUBLE", "DOUBLE PRECISION", "REAL":
+		return decimalColumnKind
+	}
+	return ""
+}
+
+// numericColumnKinds returns numeric column kinds by record column name using scanner column types, mapping renames scanner columns
+func numericColumnKinds(scanner dsc.Scanner, mapping map[string]string) map[string]string {
+	var result = make(map[string]string)
+	names, err := scanner.Columns()
+	if err != nil {
+		return result
+	}
+	types, err := scanner.ColumnTypes()
+	if err != nil {
+		return result
+	}
+	for i, name := range names {
+		if i >= len(types) {
+			break
+		}
+		if kind := numericColumnKind(types[i].DatabaseTypeName()); kind != "" {
+			if mapped, ok := mapping[name]; ok {
+				name = mapped
+			}
+			result[name] = kind
+		}
+	}
+	return result
+}
+
+// normalizeRecord converts byte slices to text, numeric columns text is converted to int64 or json.Number, so that numeric keys compare numerically
+func normalizeRecord(record map[string]interface{}, kinds map[string]string) {
+	for column, value := range record {
+		if bytes, ok := value.([]byte); ok {
+			value = string(bytes)
+			record[column] = value
+		}
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+		switch kinds[column] {
+		case integerColumnKind:
+			if number, err := strconv.ParseInt(text, 10, 64); err == nil {
+				record[column] = number
+			}
+		case decimalColumnKind:
+			if _, err := strconv.ParseFloat(text, 64); err == nil {
+				record[column] = json.Number(text)
+			}
+		}
+	}
+}
+
+// textRecordReader returns record reader normalizing values with scanner column types, so that values compare the same way after spilling
+func textRecordReader(read func(scanner dsc.Scanner) (map[string]interface{}, error), mapping map[string]string) func(scanner dsc.Scanner) (map[string]interface{}, error) {
+	var kinds map[string]string
+	return func(scanner dsc.Scanner) (map[string]interface{}, error) {
+		if kinds == nil {
+			kinds = numericColumnKinds(scanner, mapping)
+		}
+		record, err := read(scanner)
+		if err != nil {
+			return nil, err
+		}
+		normalizeRecord(record, kinds)
+		return record, nil
+	}
+}
+
+// cursorIterator streams datastore records, records are expected to be ordered by index columns
+type cursorIterator struct {
+	source   string
+	indexBy  []string
+	records  chan map[string]interface{}
+	done     chan bool
+	closed   bool
+	err      error
+	previous map[string]interface{}
+	count    *int
+}
+
+// Next returns next cursor record, it fails if records are not ordered by index columns
+func (i *cursorIterator) Next() (map[string]interface{}, bool, error) {
+	record, ok := <-i.records
+	if !ok {
+		return nil, false, i.err
+	}
+	if i.previous != nil && compareKeys(i.indexBy, i.previous, record) > 0 {
+		return nil, false, fmt.Errorf("%v records are not ordered by %v: [%v] follows [%v], consider external sort", i.source, i.indexBy, keyPath(i.indexBy, record), keyPath(i.indexBy, i.previous))
+	}
+	i.previous = record
+	*i.count++
+	return record, true, nil
+}
+
+// Close stops cursor reader
+func (i *cursorIterator) Close() error {
+	if i.closed {
+		return nil
+	}
+	i.closed = true
+	close(i.done)
+	for range i.records {
+	}
+	return nil
+}
+
+func newCursorIterator(source string, manager dsc.Manager, SQL string, read func(scanner dsc.Scanner) (map[string]interface{}, error), indexBy []string, count *int) *cursorIterator {
+	result := &cursorIterator{
+		source:  source,
+		indexBy: indexBy,
+		records: make(chan map[string]interface{}, cursorBufferSize),
+		done:    make(chan bool),
+		count:   count,
+	}
+	go func() {
+		defer close(result.records)
+		result.err = manager.ReadAllWithHandler(SQL, nil, func(scanner dsc.Scanner) (bool, error) {
+			record, err := read(scanner)
+			if err != nil {
+				return false, err
+			}
+			select {
+			case result.records <- record:
+				return true, nil
+			case <-result.done:
+				return false, nil
+			}
+		})
+	}()
+	return result
+}
+
+// externalSorter sorts records by index columns, sorted runs are spilled to disk as JSON lines
+type externalSorter struct {
+	indexBy    []string
+	bufferSize int
+	dir        string
+	buffer     []map[string]interface{}
+	runs       []string
+	count      int
+}
+
+// add adds record, buffer is spilled once it reaches buffer size
+func (s *externalSorter) add(record map[string]interface{}) error {
+	s.buffer = append(s.buffer, record)
+	s.count++
+	if len(s.buffer) >= s.bufferSize {
+		return s.spill()
+	}
+	return nil
+}
+
+// spill writes sorted buffer to a run file
+func (s *externalSorter) spill() error {
+	sort.SliceStable(s.buffer, func(i, j int) bool {
+		return compareKeys(s.indexBy, s.buffer[i], s.buffer[j]) < 0
+	})
+	file, err := ioutil.TempFile(s.dir, "dsunit_sort_")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, file.Name())
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range s.buffer {
+		if err = encoder.Encode(record); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	s.buffer = s.buffer[:0]
+	return err
+}
+
+// iterator returns k-way merge iterator over sorted runs, all records are spilled so that values have the same types regardless of the dataset size
+func (s *externalSorter) iterator() (recordIterator, error) {
+	if len(s.buffer) > 0 || len(s.runs) == 0 {
+		if err := s.spill(); err != nil {
+			s.remove()
+			return nil, err
+		}
+	}
+	result := &mergeIterator{indexBy: s.indexBy, runs: s.runs}
+	s.runs = nil
+	for _, name := range result.runs {
+		file, err := os.Open(name)
+		if err != nil {
+			_ = result.Close()
+			return nil, err
+		}
+		decoder := json.NewDecoder(bufio.NewReader(file))
+		decoder.UseNumber()
+		reader := &runReader{file: file, decoder: decoder}
+		result.files = append(result.files, file)
+		has, err := reader.next()
+		if err != nil {
+			_ = result.Close()
+			return nil, err
+		}
+		if has {
+			result.readers = append(result.readers, reader)
+		}
+	}
+	heap.Init(result)
+	return result, nil
+}
+
+// remove removes spilled runs
+func (s *externalSorter) remove() {
+	for _, name := range s.runs {
+		_ = os.Remove(name)
+	}
+	s.runs = nil
+}
+
+func newExternalSorter(indexBy []string, bufferSize int, dir string) *externalSorter {
+	if bufferSize <= 0 {
+		bufferSize = defaultSortBufferSize
+	}
+	return &externalSorter{indexBy: indexBy, bufferSize: bufferSize, dir: dir}
+}
+
+// runReader reads a sorted run
+type runReader struct {
+	file    *os.File
+	decoder *json.Decoder
+	record  map[string]interface{}
+}
+
+func (r *runReader) next() (bool, error) {
+	r.record = make(map[string]interface{})
+	err := r.decoder.Decode(&r.record)
+	if err == io.EOF {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// mergeIterator merges sorted runs
+type mergeIterator struct {
+	indexBy []string
+	runs    []string
+	files   []*os.File
+	readers []*runReader
+}
+
+func (i *mergeIterator) Len() int {
+	return len(i.readers)
+}
+
+func (i *mergeIterator) Less(x, y int) bool {
+	return compareKeys(i.indexBy, i.readers[x].record, i.readers[y].record) < 0
+}
+
+func (i *mergeIterator) Swap(x, y int) {
+	i.readers[x], i.readers[y] = i.readers[y], i.readers[x]
+}
+
+func (i *mergeIterator) Push(item interface{}) {
+	i.readers = append(i.readers, item.(*runReader))
+}
+
+func (i *mergeIterator) Pop() interface{} {
+	last := i.readers[len(i.readers)-1]
+	i.readers = i.readers[:len(i.readers)-1]
+	return last
+}
+
+// Next returns the smallest record across runs
+func (i *mergeIterator) Next() (map[string]interface{}, bool, error) {
+	if len(i.readers) == 0 {
+		return nil, false, nil
+	}
+	reader := i.readers[0]
+	record := reader.record
+	has, err := reader.next()
+	if err != nil {
+		return nil, false, err
+	}
+	if has {
+		heap.Fix(i, 0)
+	} else {
+		heap.Pop(i)
+	}
+	return record, true, nil
+}
+
+// Close closes and removes run files
+func (i *mergeIterator) Close() error {
+	for _, file := range i.files {
+		_ = file.Close()
+	}
+	for _, name := range i.runs {
+		_ = os.Remove(name)
+	}
+	i.files, i.runs, i.readers = nil, nil, nil
+	return nil
+}
+
+// sortedIterators reads both sources into external sorters and returns merge iterators
+func (s *service) sortedIterators(manager1, manager2 dsc.Manager, request *CompareRequest, response *CompareResponse, indexBy []string) (recordIterator, recordIterator, error) {
+	read1 := textRecordReader(recordReader(request, true), request.ColumnMapping)
+	read2 := textRecordReader(recordReader(request, false), nil)
+	sorter1 := newExternalSorter(indexBy, request.SortBufferSize, request.TempDir)
+	sorter2 := newExternalSorter(indexBy, request.SortBufferSize, request.TempDir)
+	defer sorter1.remove()
+	defer sorter2.remove()
+	var err1, err2 error
+	sortRecords := func(manager dsc.Manager, SQL string, read func(scanner dsc.Scanner) (map[string]interface{}, error), sorter *externalSorter) error {
+		return manager.ReadAllWithHandler(SQL, nil, func(scanner dsc.Scanner) (bool, error) {
+			record, err := read(scanner)
+			if err == nil {
+				err = sorter.add(record)
+			}
+			return err == nil, err
+		})
+	}
+	waitGroup := &sync.WaitGroup{}
+	waitGroup.Add(2)
+	go func() {
+		defer waitGroup.Done()
+		err1 = sortRecords(manager1, request.Source1.SQL, read1, sorter1)
+	}()
+	go func() {
+		defer waitGroup.Done()
+		err2 = sortRecords(manager2, request.Source2.SQL, read2, sorter2)
+	}()
+	waitGroup.Wait()
+	if err1 != nil {
+		return nil, nil, err1
+	}
+	if err2 != nil {
+		return nil, nil, err2
+	}
+	response.Dataset1Count = sorter1.count
+	response.Dataset2Count = sorter2.count
+	iter1, err := sorter1.iterator()
+	if err != nil {
+		return nil, nil, err
+	}
+	iter2, err := sorter2.iterator()
+	if err != nil {
+		_ = iter1.Close()
+		return nil, nil, err
+	}
+	return iter1, iter2, nil
+}
+
+// streamCompare merge joins both sources by index columns, sources are streamed directly if ordered, otherwise sorted with external sort
+func (s *service) streamCompare(manager1, manager2 dsc.Manager, request *CompareRequest, response *CompareResponse, report *discrepancyReport) {
+	indexBy := request.IndexBy()
+	if len(indexBy) == 0 {
+		response.SetError(errors.New("stream compare requires " + assertly.IndexByDirective + " directive"))
+		return
+	}
+	var iter1, iter2 recordIterator
+	if request.Ordered {
+		iter1 = newCursorIterator("source1", manager1, request.Source1.SQL, textRecordReader(recordReader(request, true), request.ColumnMapping), indexBy, &response.Dataset1Count)
+		iter2 = newCursorIterator("source2", manager2, request.Source2.SQL, textRecordReader(recordReader(request, false), nil), indexBy, &response.Dataset2Count)
+	} else {
+		var err error
+		if iter1, iter2, err = s.sortedIterators(manager1, manager2, request, response, indexBy); err != nil {
+			response.SetError(err)
+			return
+		}
+	}
+	defer func() {
+		_ = iter1.Close()
+		_ = iter2.Close()
+	}()
+	if err := s.mergeCompare(iter1, iter2, request, response, indexBy, report); err != nil {
+		response.SetError(err)
+		return
+	}
+	if response.Dataset1Count == 0 && response.Dataset2Count == 0 {
+		response.AddFailure(assertly.NewFailure("", "", "no data", response.Dataset1Count, response.Dataset2Count))
+	}
+}
+
+// mergeCompare compares records with matching index columns, unmatched records are reported as missing or extra, all discrepancies are written to the report while failures are limited by MaxRowDiscrepancy
+func (s *service) mergeCompare(iter1, iter2 recordIterator, request *CompareRequest, response *CompareResponse, indexBy []string, report *discrepancyReport) error {
+	record1, has1, err := iter1.Next()
+	if err != nil {
+		return err
+	}
+	record2, has2, err := iter2.Next()
+	if err != nil {
+		return err
+	}
+	discrepantRowCount := 0
+	addFailure := func(failure *assertly.Failure) {
+		if request.MaxRowDiscrepancy == 0 || discrepantRowCount <= request.MaxRowDiscrepancy {
+			response.AddFailure(failure)
+		}
+	}
+	for has1 || has2 {
+		order := 0
+		switch {
+		case !has2:
+			order = -1
+		case !has1:
+			order = 1
+		default:
+			order = compareKeys(indexBy, record1, record2)
+		}
+		switch {
+		case order < 0:
+			path := keyPath(indexBy, record1)
+			discrepantRowCount++
+			response.MissingRows++
+			addFailure(assertly.NewFailure("", path, "missing record in source2", path, nil))
+			if err = report.write(&Discrepancy{Type: DiscrepancyMissing, Key: recordKey(indexBy, record1), Source1: reportRecord(record1)}); err == nil {
+				record1, has1, err = iter1.Next()
+			}
+		case order > 0:
+			path := keyPath(indexBy, record2)
+			discrepantRowCount++
+			response.ExtraRows++
+			addFailure(assertly.NewFailure("", path, "unexpected record in source2", nil, path))
+			if err = report.write(&Discrepancy{Type: DiscrepancyExtra, Key: recordKey(indexBy, record2), Source2: reportRecord(record2)}); err == nil {
+				record2, has2, err = iter2.Next()
+			}
+		default:
+			path := keyPath(indexBy, record1)
+			removeIgnoredColumns(request, record1, record2)
+			var discrepancy *Discrepancy
+			if report != nil {
+				discrepancy = &Discrepancy{Type: DiscrepancyMismatch, Key: recordKey(indexBy, record1), Source1: reportRecord(record1), Source2: reportRecord(record2)}
+			}
+			request.ApplyDirective(record1)
+			var validation *assertly.Validation
+			if validation, err = assertly.Assert(record1, record2, assertly.NewDataPath(path)); err != nil {
+				return err
+			}
+			response.PassedCount += validation.PassedCount
+			if validation.HasFailure() {
+				discrepantRowCount++
+				response.MismatchedRows++
+				for _, failure := range validation.Failures {
+					addFailure(failure)
+				}
+				if discrepancy != nil {
+					discrepancy.Columns = failedColumns(validation.Failures, record1, record2)
+					err = report.write(discrepancy)
+				}
+			} else {
+				response.MatchedRows++
+			}
+			if err == nil {
+				if record1, has1, err = iter1.Next(); err == nil {
+					record2, has2, err = iter2.Next()
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if report == nil && request.MaxRowDiscrepancy > 0 && discrepantRowCount >= request.MaxRowDiscrepancy {
+			return nil
+		}
+	}
+	return nil
+}
+
+// sliceIterator iterates records sorted by index columns
+type sliceIterator struct {
+	records []map[string]interface{}
+	index   int
+}
+
+// Next returns next record
+func (i *sliceIterator) Next() (map[string]interface{}, bool, error) {
+	if i.index >= len(i.records) {
+		return nil, false, nil
+	}
+	i.index++
+	return i.records[i.index-1], true, nil
+}
+
+// Close releases records
+func (i *sliceIterator) Close() error {
+	i.records = nil
+	return nil
+}
+
+// unmatchedRows returns number of source1 records without source2 match (missing) and source2 records without source1 match (extra), records are matched by index columns or position when no index is defined
+func unmatchedRows(iter1, iter2 toolbox.Iterator, indexBy []string) (missing, extra int, err error) {
+	var counts = make(map[string]int)
+	for i, iterator := range []toolbox.Iterator{iter1, iter2} {
+		delta := 1
+		if i == 1 {
+			delta = -1
+		}
+		position := 0
+		for iterator.HasNext() {
+			var record map[string]interface{}
+			if err = iterator.Next(&record); err != nil {
+				return 0, 0, err
+			}
+			key := toolbox.AsString(position)
+			if len(indexBy) > 0 {
+				key = keyPath(indexBy, record)
+			}
+			counts[key] += delta
+			position++
+		}
+	}
+	for _, count := range counts {
+		if count > 0 {
+			missing += count
+		} else {
+			extra -= count
+		}
+	}
+	return missing, extra, nil
+}
+
+// newSliceIterator returns iterator over supplied records sorted by index columns
+func newSliceIterator(iterator toolbox.Iterator, indexBy []string) (*sliceIterator, error) {
+	var records = make([]map[string]interface{}, 0)
+	for iterator.HasNext() {
+		var record map[string]interface{}
+		if err := iterator.Next(&record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return compareKeys(indexBy, records[i], records[j]) < 0
+	})
+	return &sliceIterator{records: records}, nil
+}
@@ -0,0 +1,80 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCompareValues(t *testing.T) {
+	assert.Equal(t, -1, compareValues(2, 10))
+	assert.Equal(t, 0, compareValues(int64(3), "3"))
+	assert.Equal(t, 1, compareValues("b", "a"))
+	assert.Equal(t, -1, compareValues(nil, "a"))
+	assert.Equal(t, 1, compareValues("9", "10"))
+	assert.Equal(t, -1, compareValues(2.5, int64(3)))
+	assert.Equal(t, 1, compareValues(int64(9007199254740993), int64(9007199254740992)))
+	assert.Equal(t, 1, compareValues(json.Number("9007199254740993"), int64(9007199254740992)))
+	assert.Equal(t, 0, compareValues(json.Number("9007199254740993"), uint64(9007199254740993)))
+	assert.Equal(t, -1, compareValues(float64(9007199254740992), int64(9007199254740993)))
+}
+
+func TestNormalizeRecord(t *testing.T) {
+	kinds := map[string]string{"id": numericColumnKind("UNSIGNED BIGINT"), "amount": numericColumnKind("DECIMAL(10,2)")}
+	assert.Equal(t, "", numericColumnKind("INTERVAL"))
+	record1 := map[string]interface{}{"id": []byte("9"), "amount": []byte("10.50"), "name": []byte("b")}
+	record2 := map[string]interface{}{"id": []byte("10"), "amount": []byte("9.5"), "name": []byte("a")}
+	normalizeRecord(record1, kinds)
+	normalizeRecord(record2, kinds)
+	assert.EqualValues(t, map[string]interface{}{"id": int64(9), "amount": json.Number("10.50"), "name": "b"}, record1)
+	assert.Equal(t, -1, compareKeys([]string{"id"}, record1, record2))
+	assert.Equal(t, 1, compareKeys([]string{"amount"}, record1, record2))
+	assert.Equal(t, 1, compareKeys([]string{"name"}, record1, record2))
+}
+
+func TestUnmatchedRows(t *testing.T) {
+	records1 := []map[string]interface{}{{"id": 1}, {"id": 2}, {"id": 3}, {"id": 3}}
+	records2 := []map[string]interface{}{{"id": 2}, {"id": 3}, {"id": 4}, {"id": 5}, {"id": 6}}
+	missing, extra, err := unmatchedRows(toolbox.NewSliceIterator(records1), toolbox.NewSliceIterator(records2), []string{"id"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, missing)
+	assert.Equal(t, 3, extra)
+
+	missing, extra, err = unmatchedRows(toolbox.NewSliceIterator(records1), toolbox.NewSliceIterator(records2), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, missing)
+	assert.Equal(t, 1, extra)
+}
+
+func TestExternalSorter_Iterator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dsunit_sort")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	sorter := newExternalSorter([]string{"id", "seq"}, 3, dir)
+	for _, id := range []int{7, 3, 11, 1, 3, 5, 2, 9} {
+		assert.Nil(t, sorter.add(map[string]interface{}{"id": id, "seq": 10 - id, "name": "n"}))
+	}
+	assert.Nil(t, sorter.add(map[string]interface{}{"id": 3, "seq": 1, "name": "n"}))
+	assert.Equal(t, 9, sorter.count)
+	iterator, err := sorter.iterator()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var paths = make([]string, 0)
+	for {
+		record, has, err := iterator.Next()
+		if !assert.Nil(t, err) || !has {
+			break
+		}
+		paths = append(paths, keyPath([]string{"id", "seq"}, record))
+	}
+	assert.EqualValues(t, []string{"id:1, seq:9", "id:2, seq:8", "id:3, seq:1", "id:3, seq:7", "id:3, seq:7", "id:5, seq:5", "id:7, seq:3", "id:9, seq:1", "id:11, seq:-1"}, paths)
+	assert.Nil(t, iterator.Close())
+	files, _ := ioutil.ReadDir(dir)
+	assert.Equal(t, 0, len(files))
+}
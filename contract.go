@@ -9,6 +9,7 @@ import (
 	"github.com/viant/dsc"
 	dsurl "github.com/viant/dsunit/url"
 	"github.com/viant/toolbox"
+	"regexp"
 	"strings"
 	"sync"
 )
@@ -131,9 +132,21 @@ type RecreateResponse struct {
 
 // RunSQLRequest represents run SQL request
 type RunSQLRequest struct {
-	Datastore string `required:"true" description:"registered datastore name"`
-	Expand    bool   `description:"substitute $ expression with content of context.state"`
-	SQL       []string
+	Datastore   string `required:"true" description:"registered datastore name"`
+	Expand      bool   `description:"substitute $ expression with content of context.state"`
+	SQL         []string
+	Expect      map[int][]map[string]interface{} `description:"expected records by zero based SQL index, only statements returning records i.e. SELECT or CALL can be verified"`
+	CheckPolicy int                              `description:"0 - FullTableDatasetCheckPolicy, 1 - SnapshotDatasetCheckPolicy (only expected records are verified, use @indexBy@)"`
+}
+
+// Validate checks if request is valid
+func (r *RunSQLRequest) Validate() error {
+	for index := range r.Expect {
+		if index < 0 || index >= len(r.SQL) {
+			return fmt.Errorf("expect index %v is out of SQL range: %v", index, len(r.SQL))
+		}
+	}
+	return nil
 }
 
 // NewRunSQLRequest creates new run SQL request
@@ -156,13 +169,30 @@ func NewRunSQLRequestFromURL(URL string) (*RunSQLRequest, error) {
 type RunSQLResponse struct {
 	*BaseResponse
 	RowsAffected int
+	Statements   []*StatementResult `json:",omitempty" description:"statements results, the last one has error if execution failed"`
+	PassedCount  int                `description:"passed expected records assertions"`
+	FailedCount  int                `description:"failed expected records assertions"`
+}
+
+// StatementResult represents statement execution result
+type StatementResult struct {
+	URL          string `json:",omitempty"`
+	Line         int    `json:",omitempty"`
+	Column       int    `json:",omitempty"`
+	SQL          string
+	RowsAffected int
+	ElapsedMs    int
+	Records      Records              `json:",omitempty" description:"records returned by SELECT, CALL and other result producing statements"`
+	Validation   *assertly.Validation `json:",omitempty"`
+	Error        string               `json:",omitempty"`
 }
 
 // RunScriptRequest represents run SQL Script request
 type RunScriptRequest struct {
-	Datastore string `required:"true" description:"registered datastore name"`
-	Expand    bool   `description:"substitute $ expression with content of context.state"`
-	Scripts   []*dsurl.Resource
+	Datastore     string `required:"true" description:"registered datastore name"`
+	Expand        bool   `description:"substitute $ expression with content of context.state"`
+	Transactional bool   `description:"runs all scripts in one transaction rolled back on error, DDL is only allowed for vendors with transactional DDL"`
+	Scripts       []*dsurl.Resource
 }
 
 // NewRunScriptRequest creates new run script request
@@ -181,6 +211,72 @@ func NewRunScriptRequestFromURL(URL string) (*RunScriptRequest, error) {
 	return result, err
 }
 
+const defaultMigrationTable = "dsunit_migrations"
+
+// MigrateRequest represents versioned migration scripts request, scripts are named V1__desc.sql (Flyway), 0001_desc.up.sql (golang-migrate, down scripts are ignored) or 1_desc.sql
+type MigrateRequest struct {
+	Datastore     string `required:"true" description:"registered datastore name"`
+	URL           string `required:"true" description:"migration scripts directory URL"`
+	Table         string `description:"applied migrations tracking table, dsunit_migrations by default"`
+	Expand        bool   `description:"substitute $ expression with content of context.state"`
+	Transactional bool   `description:"runs each script in a transaction, DDL is only allowed for vendors with transactional DDL"`
+}
+
+// Init initialises request
+func (r *MigrateRequest) Init() error {
+	if r.Table == "" {
+		r.Table = defaultMigrationTable
+	}
+	if r.URL != "" {
+		r.URL = url.Normalize(r.URL, file.Scheme)
+	}
+	return nil
+}
+
+// Validate checks if request is valid
+func (r *MigrateRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	if r.URL == "" {
+		return errors.New("url was empty")
+	}
+	return nil
+}
+
+// NewMigrateRequest creates a new migrate request
+func NewMigrateRequest(datastore, URL string) *MigrateRequest {
+	return &MigrateRequest{
+		Datastore: datastore,
+		URL:       URL,
+	}
+}
+
+// NewMigrateRequestFromURL create a request from URL
+func NewMigrateRequestFromURL(URL string) (*MigrateRequest, error) {
+	var result = &MigrateRequest{}
+	location := url.Normalize(URL, file.Scheme)
+	err := dsurl.Decode(location, result)
+	return result, err
+}
+
+// Migration represents versioned migration script
+type Migration struct {
+	Version     string
+	Description string
+	Script      string
+	Checksum    string
+}
+
+// MigrateResponse represents migrate response
+type MigrateResponse struct {
+	*BaseResponse
+	Version    string             `description:"the latest applied version"`
+	Applied    []*Migration       `description:"migrations applied by this request"`
+	Drifted    []*Migration       `description:"applied migrations with changed script checksum, pending migrations are not run if any"`
+	Statements []*StatementResult `json:",omitempty" description:"failed migration script statements results"`
+}
+
 // MappingRequest represnet a mapping request
 type MappingRequest struct {
 	Mappings []*Mapping `required:"true" description:"virtual table mapping"`
@@ -243,8 +339,9 @@ type MappingResponse struct {
 
 // InitRequest represents datastore init request, it actual aggregates, registraction, recreation, mapping and run script request
 type InitRequest struct {
-	Datastore string
-	Recreate  bool
+	Datastore     string
+	Recreate      bool
+	MigrationsURL string `description:"versioned migration scripts URL, only pending scripts are run instead of Scripts"`
 	*RegisterRequest
 	Admin *RegisterRequest
 	*MappingRequest
@@ -287,6 +384,9 @@ func (r *InitRequest) Validate() error {
 	if r.RegisterRequest.Config == nil {
 		return errors.New("register request config was empty")
 	}
+	if r.MigrationsURL != "" && r.RunScriptRequest != nil && len(r.Scripts) > 0 {
+		return errors.New("migrationsURL and scripts were both specified")
+	}
 	return nil
 }
 
@@ -320,6 +420,8 @@ type InitResponse struct {
 type PrepareRequest struct {
 	Expand           bool `description:"substitute $ expression with content of context.state"`
 	Threads          int
+	SyncSequences    bool              `description:"sets prepared tables sequence or identity next value to max(pk)+1"`
+	Sequences        map[string]string `description:"sequence name by table, used by SyncSequences instead of table identity"`
 	*DatasetResource `required:"true" description:"datasets resource"`
 }
 
@@ -364,15 +466,19 @@ type ModificationInfo struct {
 // PrepareResponse represents a prepare response
 type PrepareResponse struct {
 	*BaseResponse
-	Expand       bool                         `description:"substitute $ expression with content of context.state"`
-	Modification map[string]*ModificationInfo `description:"modification info by subject"`
-	mux          sync.Mutex
+	Expand           bool                         `description:"substitute $ expression with content of context.state"`
+	Modification     map[string]*ModificationInfo `description:"modification info by subject"`
+	Sequences        map[string]int               `description:"synchronized sequence next value by table"`
+	SkippedSequences []string                     `description:"tables skipped by SyncSequences, without single column key or key sequence"`
+	mux              sync.Mutex
 }
 
 // ExpectRequest represents verification datastore request
 type ExpectRequest struct {
 	*DatasetResource
-	CheckPolicy int `required:"true" description:"0 - FullTableDatasetCheckPolicy, 1 - SnapshotDatasetCheckPolicy"`
+	CheckPolicy     int           `required:"true" description:"0 - FullTableDatasetCheckPolicy, 1 - SnapshotDatasetCheckPolicy"`
+	Encrypted       []Obfuscation `description:"reversible obfuscation rules (cipher, fpe) for encrypted columns, actual values are decrypted before comparison"`
+	EncryptExpected bool          `description:"flag to encrypt expected values instead of decrypting actual values"`
 }
 
 // Validate checks if request is valid
@@ -386,6 +492,11 @@ func (r *ExpectRequest) Validate() error {
 	if r.DatastoreDatasets == nil {
 		return errors.New("datastore was empty")
 	}
+	for i, rule := range r.Encrypted {
+		if !rule.IsReversible() {
+			return fmt.Errorf("encrypted[%v].method: %v is not reversible", i, rule.Method)
+		}
+	}
 	return nil
 }
 
@@ -440,12 +551,76 @@ type SequenceResponse struct {
 	Sequences map[string]int
 }
 
+// SyncSequencesRequest represents sequences synchronization request
+type SyncSequencesRequest struct {
+	Datastore string
+	Tables    []string
+	Sequences map[string]string `description:"sequence name by table, table identity or autoincrement is synchronized if not specified"`
+}
+
+// Validate checks if request is valid
+func (r *SyncSequencesRequest) Validate() error {
+	if len(r.Tables) == 0 {
+		return errors.New("tables were empty")
+	}
+	return nil
+}
+
+// NewSyncSequencesRequest creates a new sequences synchronization request
+func NewSyncSequencesRequest(datastore string, tables ...string) *SyncSequencesRequest {
+	return &SyncSequencesRequest{
+		Datastore: datastore,
+		Tables:    tables,
+	}
+}
+
+// SyncSequencesResponse represents sequences synchronization response
+type SyncSequencesResponse struct {
+	*BaseResponse
+	Sequences map[string]int `description:"sequence next value by table"`
+	Skipped   []string       `description:"tables without single column key or key sequence"`
+}
+
 // QueryRequest represents get sequences request
 type QueryRequest struct {
-	Datastore   string
-	SQL         string
-	IgnoreError bool
-	Expect      []map[string]interface{} `description:"if specified validation would take place"`
+	Datastore     string
+	SQL           string
+	IgnoreError   bool
+	Expect        []map[string]interface{} `description:"if specified validation would take place"`
+	Params        []interface{}            `description:"positional bind parameters for ? placeholders"`
+	NamedParams   map[string]interface{}   `description:"named bind parameters for :name placeholders"`
+	ExpectDataset *DatasetResource         `description:"expected dataset resource, records support the same directives as Expect i.e. @indexBy@"`
+	Dataset       string                   `description:"expected dataset name, the only loaded dataset is used if empty"`
+	CheckPolicy   int                      `description:"expectDataset check policy: 0 - FullTableDatasetCheckPolicy, 1 - SnapshotDatasetCheckPolicy (only expected records are verified, use @indexBy@)"`
+	DestURL       string                   `description:"if specified, records are streamed to this location instead of response records"`
+	Format        string                   `description:"export format: csv, tsv, ndjson or json, derived from DestURL extension if empty, ndjson by default, time values use RFC3339 in all formats"`
+}
+
+// Init initialises request
+func (r *QueryRequest) Init() error {
+	if r.ExpectDataset != nil && r.ExpectDataset.DatastoreDatasets == nil {
+		r.ExpectDataset.DatastoreDatasets = &DatastoreDatasets{Datastore: r.Datastore}
+	}
+	return nil
+}
+
+// Validate checks if request is valid
+func (r *QueryRequest) Validate() error {
+	if len(r.Params) > 0 && len(r.NamedParams) > 0 {
+		return errors.New("params and namedParams were both specified")
+	}
+	if len(r.Expect) > 0 && r.ExpectDataset != nil {
+		return errors.New("expect and expectDataset were both specified")
+	}
+	if r.DestURL != "" && r.HasExpect() {
+		return errors.New("destURL export does not support expect")
+	}
+	return nil
+}
+
+// HasExpect returns true if query records are verified
+func (r *QueryRequest) HasExpect() bool {
+	return len(r.Expect) > 0 || r.ExpectDataset != nil
 }
 
 func NewQueryRequest(datastore, SQL string) *QueryRequest {
@@ -459,6 +634,8 @@ func NewQueryRequest(datastore, SQL string) *QueryRequest {
 type QueryResponse struct {
 	*BaseResponse
 	Records Records
+	Count   int    `description:"exported record count"`
+	DestURL string `description:"export location"`
 	*assertly.Validation
 }
 
@@ -479,9 +656,30 @@ type (
 		Reset            bool              `description:"add extra empty record to truncate before inserting"`
 		TimeFormat       string            `description:"java/ios based time format"`
 		TimeLayout       string            `description:"golang based time layout"`
+		PII              *PIIScan          `description:"if specified frozen records are scanned for unmasked PII"`
+		Table            string            `description:"source table used to lookup primary key, extracted from SQL if empty"`
+		KeyDirective     bool              `description:"emit @indexBy@ or @autoincrement@ directive record with table primary key"`
+		SymbolicIDs      bool              `description:"rewrite autoincrement ids into <ds:seq[table, offset]> expressions"`
+		References       map[string]string `description:"FK column to referenced table, values are rewritten into <ds:seq[table, offset]> expressions"`
+		ExpectMode       bool              `description:"expect file mode, volatile values (timestamps, UUIDs, hashes, tokens) are replaced with predicates"`
+		Volatile         []string          `description:"additional volatile columns for expect file mode"`
+		TimeWindowSec    int               `description:"expect file mode timestamp within_sec predicate window, @exists@ is used if not set"`
 	}
 )
 
+var fromTableExpr = regexp.MustCompile(`(?i)\bfrom\s+([\w.]+)`)
+
+// TableName returns freeze table or the first table referenced by SQL
+func (r *FreezeRequest) TableName() string {
+	if r.Table != "" {
+		return r.Table
+	}
+	if matched := fromTableExpr.FindStringSubmatch(r.SQL); len(matched) > 1 {
+		return matched[1]
+	}
+	return ""
+}
+
 func (r *FreezeRequest) Init() error {
 	if r.TimeLayout == "" && r.TimeFormat != "" {
 		r.TimeLayout = toolbox.DateFormatToLayout(r.TimeFormat)
@@ -489,13 +687,189 @@ func (r *FreezeRequest) Init() error {
 	return nil
 }
 
+// maskedColumns returns columns obfuscated, overridden, ignored or excluded from PII scan
+func (r *FreezeRequest) maskedColumns() map[string]bool {
+	var result = make(map[string]bool)
+	for _, rule := range r.Obfuscation {
+		for _, column := range rule.Columns {
+			result[column] = true
+		}
+	}
+	for column := range r.Override {
+		result[column] = true
+	}
+	for _, column := range r.Ignore {
+		result[column] = true
+	}
+	if r.PII != nil {
+		for _, column := range r.PII.Ignore {
+			result[column] = true
+		}
+	}
+	return result
+}
+
 // FreezeResponse response
 type FreezeResponse struct {
 	*BaseResponse
 	Count   int
 	DestURL string
+	PII     *PIIReport `description:"PII scan report"`
+}
+
+// ScaffoldRequest represents a request to generate test setup (prepare, expect datasets and go test skeleton) from existing database
+type ScaffoldRequest struct {
+	Datastore   string            `required:"true" description:"registered datastore i.e. db1"`
+	Tables      []string          `description:"tables to freeze with SELECT * FROM <table>"`
+	SQLs        map[string]string `description:"table to dataset SQL, takes precedence over tables"`
+	Test        string            `required:"true" description:"go test name i.e. TestUserService_Create"`
+	DestURL     string            `required:"true" description:"test package location, datasets and test file are written there"`
+	TestFile    string            `description:"test file name without .go extension, derived from test name if empty i.e. user_service_test"`
+	Package     string            `description:"go package name, taken from existing dest go files or derived from dest directory if empty"`
+	InitURL     string            `description:"dsunit init request URL used by the generated test, defaults to test/init.json"`
+	CheckPolicy int               `description:"0 - FullTableDatasetCheckPolicy, 1 - SnapshotDatasetCheckPolicy"`
+	Freeze      *FreezeRequest    `description:"freeze options (obfuscation, timezone, ignore, PII) applied to each dataset, Datastore, SQL and DestURL are set by scaffold"`
+	Overwrite   bool              `description:"flag to overwrite existing test file"`
+}
+
+// Init initialises request
+func (r *ScaffoldRequest) Init() error {
+	if r.InitURL == "" {
+		r.InitURL = "test/init.json"
+	}
+	if r.TestFile == "" {
+		name := strings.TrimPrefix(r.Test, "Test")
+		if index := strings.Index(name, "_"); index > 0 {
+			name = string(name[:index])
+		}
+		r.TestFile = convertToLowerUnderscore(name) + "_test"
+	}
+	r.TestFile = strings.TrimSuffix(r.TestFile, ".go")
+	if r.DestURL != "" {
+		r.DestURL = url.Normalize(r.DestURL, file.Scheme)
+	}
+	if r.Freeze == nil {
+		r.Freeze = &FreezeRequest{}
+	}
+	return nil
+}
+
+// Validate checks if request is valid
+func (r *ScaffoldRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	if r.DestURL == "" {
+		return errors.New("destURL was empty")
+	}
+	if !strings.HasPrefix(r.Test, "Test") {
+		return fmt.Errorf("invalid test name: %v, expected Test prefix", r.Test)
+	}
+	if len(r.Tables) == 0 && len(r.SQLs) == 0 {
+		return errors.New("tables and SQLs were empty")
+	}
+	if !strings.HasSuffix(r.TestFile, "_test") {
+		return fmt.Errorf("invalid test file: %v, expected _test suffix", r.TestFile)
+	}
+	return nil
+}
+
+// DatasetSQLs returns table to dataset SQL
+func (r *ScaffoldRequest) DatasetSQLs() map[string]string {
+	var result = make(map[string]string)
+	for _, table := range r.Tables {
+		result[table] = "SELECT * FROM " + table
+	}
+	for table, SQL := range r.SQLs {
+		result[table] = SQL
+	}
+	return result
+}
+
+// NewScaffoldRequestFromURL create a request from url
+func NewScaffoldRequestFromURL(URL string) (*ScaffoldRequest, error) {
+	var result = &ScaffoldRequest{}
+	location := url.Normalize(URL, file.Scheme)
+	err := dsurl.Decode(location, result)
+	return result, err
+}
+
+// ScaffoldResponse represents a scaffold response
+type ScaffoldResponse struct {
+	*BaseResponse
+	TestURL  string   `description:"generated go test URL, empty if test file already existed"`
+	Datasets []string `description:"generated datasets URLs"`
+}
+
+const defaultCloneBatchSize = 500
+
+// CloneRequest represents a request to copy tables schema and data from source to dest datastore, including across vendors
+type CloneRequest struct {
+	Datastore     string            `description:"source registered datastore i.e. db1"`
+	DestDatastore string            `description:"dest registered datastore"`
+	Tables        []string          `description:"tables to clone, all source tables if empty"`
+	SQLs          map[string]string `description:"table to source SQL i.e. to clone subset of rows, SELECT * FROM <table> by default"`
+	MappingURL    string            `description:"data type mapping URL, see DumpRequest.MappingURL"`
+	DropTables    bool              `description:"drop and recreate existing dest tables, existing tables are kept otherwise"`
+	SkipSchema    bool              `description:"copy data only, dest tables have to exist"`
+	BatchSize     int               `description:"number of records inserted per transaction, 500 by default"`
+	Transform     *FreezeRequest    `description:"freeze transformations applied to source records i.e. Obfuscation, Override, Replace, Ignore, LocationTimezone"`
+}
+
+// Init initialises request
+func (r *CloneRequest) Init() error {
+	if r.BatchSize <= 0 {
+		r.BatchSize = defaultCloneBatchSize
+	}
+	if r.Transform == nil {
+		r.Transform = &FreezeRequest{}
+	}
+	return r.Transform.Init()
+}
+
+// Validate checks if request is valid
+func (r *CloneRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	if r.DestDatastore == "" {
+		return errors.New("destDatastore was empty")
+	}
+	if r.Datastore == r.DestDatastore {
+		return fmt.Errorf("source and dest datastore were the same: %v", r.Datastore)
+	}
+	return nil
+}
+
+// TableSQL returns table source SQL
+func (r *CloneRequest) TableSQL(table string) string {
+	if SQL, ok := r.SQLs[table]; ok {
+		return SQL
+	}
+	return "SELECT * FROM " + table
 }
 
+// NewCloneRequestFromURL creates a new clone request from URL
+func NewCloneRequestFromURL(URL string) (*CloneRequest, error) {
+	var result = &CloneRequest{}
+	location := url.Normalize(URL, file.Scheme)
+	err := dsurl.Decode(location, result)
+	return result, err
+}
+
+// CloneResponse represents a clone response
+type CloneResponse struct {
+	*BaseResponse
+	DDLs   []string       `description:"DDL executed on dest datastore"`
+	Counts map[string]int `description:"table to cloned record count"`
+}
+
+const (
+	DumpModeSchema = "schema"
+	DumpModeData   = "data"
+	DumpModeAll    = "all"
+)
+
 // DumpRequest represent a request to create a database schema
 type DumpRequest struct {
 	Datastore string   `description:"registered datastore i.e. db1"`
@@ -503,29 +877,50 @@ type DumpRequest struct {
 	DestURL   string   `description:"represent dataset destination"`
 	Target    string   `description:"target vendor, use only if different than source"`
 	/*
-		mapping url content should represents a map between source and dest data (all data type should be upper case) type i.e
+		mapping url content represents either a map between source and target type patterns, or a list of TypeMappingRule,
+		lower case parameters are placeholders, rules take precedence over default target mapping i.e.
 
 		{
 			"INT": "BIGINT",
-			"INTEGER":   "BIGINT",
-			"NUMERIC":   "DECIMAL(7,2)",
-			"FLOAT":   "DECIMAL(7,2)",
+			"NUMERIC(p,s)":   "DECIMAL(p,s)",
+			"VARCHAR(n)":   "VARCHAR(n)",
 			"VARCHAR":   "VARCHAR(255)",
-			"STRING":   "VARCHAR(255)",
-			"CHAR":      "VARCHAR(255)",
-			"DATE":      "DATE",
-			"TIMESTAMP": "TIMESTAMP",
+			"TIMESTAMP(p) WITH TIME ZONE": "TIMESTAMP(p)",
 		},
 
 	*/
-	MappingURL string `description:"if target driver is used - you can provide data type mapping"`
+	MappingURL string            `description:"if target driver is used - you can provide data type mapping"`
+	Mode       string            `description:"schema (default), data or all, data mode writes table rows as INSERT statements for target dialect"`
+	Criteria   map[string]string `description:"table to data WHERE criteria i.e. users: active = 1"`
+	BatchSize  int               `description:"rows per INSERT statement if target dialect supports multi row inserts, 1 by default"`
+}
+
+// Init initialises request
+func (r *DumpRequest) Init() error {
+	if r.Mode == "" {
+		r.Mode = DumpModeSchema
+	}
+	if r.BatchSize <= 0 {
+		r.BatchSize = 1
+	}
+	return nil
+}
+
+// Validate checks if request is valid
+func (r *DumpRequest) Validate() error {
+	switch r.Mode {
+	case DumpModeSchema, DumpModeData, DumpModeAll:
+		return nil
+	}
+	return fmt.Errorf("unsupported dump mode: %v, expected %v, %v or %v", r.Mode, DumpModeSchema, DumpModeData, DumpModeAll)
 }
 
 // DumpResponse represents a dump response
 type DumpResponse struct {
 	*BaseResponse
-	Count   int
-	DestURL string
+	Count    int
+	DestURL  string
+	Warnings []string `description:"skipped objects, i.e. views that could not be translated to target vendor"`
 }
 
 type DatastoreSQL struct {
@@ -533,6 +928,8 @@ type DatastoreSQL struct {
 	SQL       string
 }
 
+const defaultSortBufferSize = 100000
+
 // CompareRequest represent compare request
 type CompareRequest struct {
 	Source1           *DatastoreSQL
@@ -540,19 +937,55 @@ type CompareRequest struct {
 	Directives        map[string]interface{}
 	Ignore            []string // columns to ignore
 	OmitEmpty         bool
-	MaxRowDiscrepancy int //max discrepant rows
+	MaxRowDiscrepancy int                        //max discrepant rows
+	Stream            bool                       `description:"compare records streamed in @indexBy@ order with bounded memory instead of loading both datasets"`
+	Ordered           bool                       `description:"stream mode: both SQLs return records ordered by @indexBy@ columns, records are merge joined directly over cursors, otherwise records are sorted with external sort"`
+	SortBufferSize    int                        `description:"stream mode: max records sorted in memory before a sorted run is spilled to disk, 100000 by default"`
+	TempDir           string                     `description:"stream mode: external sort spill directory, os temp dir by default"`
+	Checksum          *CompareChecksum           `description:"checksum strategy: per partition counts and hashes are computed in datastores, only discrepant partitions are compared row by row, not supported with ColumnMapping and Coercions"`
+	ColumnMapping     map[string]string          `description:"source1 to source2 column name mapping, directives, ignored columns and coercions use source2 column names"`
+	Coercions         map[string]*ColumnCoercion `description:"column value coercions applied to both sources, keyed by source2 column name, * applies to columns without own coercion"`
+	DiscrepancyURL    string                     `description:"discrepancy report URL, every mismatched, missing and extra row is written regardless of MaxRowDiscrepancy, .csv extension writes CSV, NDJSON otherwise"`
 }
 
 func (r *CompareRequest) Init() error {
 	if len(r.Directives) == 0 {
 		r.Directives = make(map[string]interface{})
 	}
+	if r.SortBufferSize == 0 {
+		r.SortBufferSize = defaultSortBufferSize
+	}
 	if _, has := r.Directives[assertly.StrictMapCheckDirective]; !has {
 		r.Directives[assertly.StrictMapCheckDirective] = true
 	}
 	return nil
 }
 
+// Validate checks if request is valid
+func (r *CompareRequest) Validate() error {
+	if r.Source1 == nil {
+		return errors.New("source1 was empty")
+	}
+	if r.Source2 == nil {
+		return errors.New("source2 was empty")
+	}
+	if r.DiscrepancyURL != "" && len(r.IndexBy()) == 0 {
+		return fmt.Errorf("discrepancyURL requires %v directive", assertly.IndexByDirective)
+	}
+	if r.Checksum != nil && (len(r.ColumnMapping) > 0 || len(r.Coercions) > 0) {
+		return errors.New("checksum compare hashes raw column values, it does not support columnMapping and coercions")
+	}
+	for column, coercion := range r.Coercions {
+		if coercion == nil {
+			return fmt.Errorf("%v coercion was empty", column)
+		}
+		if err := coercion.Validate(); err != nil {
+			return fmt.Errorf("invalid %v coercion: %v", column, err)
+		}
+	}
+	return nil
+}
+
 // IndexBy returns index by directive if specified
 func (r CompareRequest) IndexBy() []string {
 	if len(r.Directives) == 0 {
@@ -579,12 +1012,160 @@ func (r *CompareRequest) ApplyDirective(record map[string]interface{}) {
 	}
 }
 
+const defaultCompareBatchConcurrency = 4
+
+// CompareBatchRequest represents a request to compare many named SQL pairs concurrently
+type CompareBatchRequest struct {
+	ManifestURL       string                     `description:"manifest URL with named Compares and shared settings, request settings take precedence"`
+	Compares          map[string]*CompareRequest `description:"named compare requests"`
+	Directives        map[string]interface{}     `description:"shared directives, compare directives take precedence"`
+	Ignore            []string                   `description:"shared columns to ignore"`
+	MaxRowDiscrepancy int                        `description:"shared max discrepant rows, used if compare does not specify one"`
+	Concurrency       int                        `description:"max number of concurrent compares, 4 by default"`
+}
+
+// Init initialises request, manifest is loaded and shared settings are applied to compares
+func (r *CompareBatchRequest) Init() error {
+	if r.ManifestURL != "" {
+		manifest, err := NewCompareBatchRequestFromURL(r.ManifestURL)
+		if err != nil {
+			return fmt.Errorf("failed to load compare manifest %v, %v", r.ManifestURL, err)
+		}
+		r.merge(manifest)
+	}
+	if r.Concurrency <= 0 {
+		r.Concurrency = defaultCompareBatchConcurrency
+	}
+	for _, compare := range r.Compares {
+		if compare == nil {
+			continue
+		}
+		if len(compare.Directives) == 0 {
+			compare.Directives = make(map[string]interface{})
+		}
+		for k, v := range r.Directives {
+			if _, has := compare.Directives[k]; !has {
+				compare.Directives[k] = v
+			}
+		}
+		compare.Ignore = append(compare.Ignore, r.Ignore...)
+		if compare.MaxRowDiscrepancy == 0 {
+			compare.MaxRowDiscrepancy = r.MaxRowDiscrepancy
+		}
+	}
+	return nil
+}
+
+// merge merges manifest compares and shared settings not specified by request
+func (r *CompareBatchRequest) merge(manifest *CompareBatchRequest) {
+	if len(r.Compares) == 0 {
+		r.Compares = make(map[string]*CompareRequest)
+	}
+	for name, compare := range manifest.Compares {
+		if _, has := r.Compares[name]; !has {
+			r.Compares[name] = compare
+		}
+	}
+	if len(r.Directives) == 0 {
+		r.Directives = make(map[string]interface{})
+	}
+	for k, v := range manifest.Directives {
+		if _, has := r.Directives[k]; !has {
+			r.Directives[k] = v
+		}
+	}
+	if len(r.Ignore) == 0 {
+		r.Ignore = manifest.Ignore
+	}
+	if r.MaxRowDiscrepancy == 0 {
+		r.MaxRowDiscrepancy = manifest.MaxRowDiscrepancy
+	}
+	if r.Concurrency == 0 {
+		r.Concurrency = manifest.Concurrency
+	}
+}
+
+// Validate checks if request is valid
+func (r *CompareBatchRequest) Validate() error {
+	if len(r.Compares) == 0 {
+		return errors.New("compares were empty")
+	}
+	for name, compare := range r.Compares {
+		if compare == nil {
+			return fmt.Errorf("%v compare was empty", name)
+		}
+		if err := compare.Validate(); err != nil {
+			return fmt.Errorf("invalid %v compare: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// NewCompareBatchRequestFromURL creates a new compare batch request from URL
+func NewCompareBatchRequestFromURL(URL string) (*CompareBatchRequest, error) {
+	var result = &CompareBatchRequest{}
+	location := url.Normalize(URL, file.Scheme)
+	err := dsurl.Decode(location, result)
+	return result, err
+}
+
+// CompareSummary represents a named compare summary
+type CompareSummary struct {
+	Name           string
+	Status         string
+	Message        string `json:",omitempty"`
+	Passed         bool   `description:"compare completed without discrepancies"`
+	Dataset1Count  int
+	Dataset2Count  int
+	MatchedRows    int
+	MismatchedRows int
+	MissingRows    int
+	ExtraRows      int
+	FailedCount    int
+}
+
+// CompareBatchResponse represents compare batch response, status is error if any compare failed to run
+type CompareBatchResponse struct {
+	*BaseResponse
+	Passed      bool              `description:"all compares completed without discrepancies"`
+	Summaries   []*CompareSummary `description:"compare summaries ordered by name"`
+	PassedCount int               `description:"compares without discrepancies"`
+	FailedCount int               `description:"compares with discrepancies or errors"`
+}
+
+// CompareChecksum represents checksum compare strategy, partitions are defined either by partition column values or key column ranges
+type CompareChecksum struct {
+	Columns         []string `description:"columns included in a row hash, values are hashed as text, timestamps (UTC), decimals and booleans are normalized by column type, JSON, binary, array and interval columns are not supported"`
+	PartitionColumn string   `description:"partition column, each distinct value defines a partition"`
+	KeyColumn       string   `description:"numeric key column split into PartitionSize ranges"`
+	PartitionSize   int      `description:"key column range size"`
+}
+
+// Validate checks if checksum strategy is valid
+func (c *CompareChecksum) Validate() error {
+	if len(c.Columns) == 0 {
+		return errors.New("checksum columns were empty")
+	}
+	if c.PartitionColumn == "" && c.KeyColumn == "" {
+		return errors.New("checksum partitionColumn or keyColumn was empty")
+	}
+	if c.PartitionColumn == "" && c.PartitionSize <= 0 {
+		return errors.New("checksum partitionSize was empty")
+	}
+	return nil
+}
+
 // CompareResponse represents compare response
 type CompareResponse struct {
 	*BaseResponse
-	Dataset1Count int
-	Dataset2Count int
-	MatchedRows   int
+	Dataset1Count        int
+	Dataset2Count        int
+	MatchedRows          int
+	MismatchedRows       int      `description:"rows with matching index columns and different values"`
+	MissingRows          int      `description:"rows present only in source1, reported by merge compare"`
+	ExtraRows            int      `description:"rows present only in source2, reported by merge compare"`
+	PartitionCount       int      `description:"checksum strategy: compared partitions"`
+	DiscrepantPartitions []string `description:"checksum strategy: partitions with discrepant count or checksum"`
 	*assertly.Validation
 }
 
@@ -596,19 +1177,90 @@ func NewDumpRequestFromURL(URL string) (*DumpRequest, error) {
 	return result, err
 }
 
-// PingRequest represents ping request
+const defaultPingDelayMs = 5000
+
+// PingRequest represents ping request, datastores are checked concurrently until they are online and ready or timeout
 type PingRequest struct {
-	Datastore string
-	TimeoutMs int
+	Datastore  string
+	TimeoutMs  int
+	Readiness  *Readiness            `description:"Datastore readiness conditions, only database ping is used if empty"`
+	Datastores map[string]*Readiness `description:"other datastores readiness conditions, only database ping is used for nil conditions"`
+	Backoff    *PingBackoff          `description:"retry delay, 5 sec fixed delay by default"`
+}
+
+// Init initialises request
+func (r *PingRequest) Init() error {
+	if r.Backoff == nil {
+		r.Backoff = &PingBackoff{}
+	}
+	if r.Backoff.DelayMs <= 0 {
+		r.Backoff.DelayMs = defaultPingDelayMs
+	}
+	if r.Backoff.Multiplier < 1 {
+		r.Backoff.Multiplier = 1
+	}
+	return nil
+}
+
+// Validate checks if request is valid
+func (r *PingRequest) Validate() error {
+	if r.Datastore == "" && len(r.Datastores) == 0 {
+		return errors.New("datastore was empty")
+	}
+	if err := r.Readiness.Validate(); err != nil {
+		return err
+	}
+	for datastore, readiness := range r.Datastores {
+		if err := readiness.Validate(); err != nil {
+			return fmt.Errorf("invalid %v readiness: %v", datastore, err)
+		}
+	}
+	return nil
+}
+
+// Readiness represents datastore readiness conditions checked after successful ping
+type Readiness struct {
+	SQL           string         `description:"probe SQL that has to succeed"`
+	Tables        []string       `description:"tables that have to exist"`
+	MinRowCount   map[string]int `description:"minimum row count by table"`
+	SchemaVersion *SchemaVersion `description:"schema version row that has to exist"`
+}
+
+// Validate checks if readiness conditions are valid, nil readiness is valid
+func (r *Readiness) Validate() error {
+	if r == nil || r.SchemaVersion == nil {
+		return nil
+	}
+	if r.SchemaVersion.Table == "" || r.SchemaVersion.Column == "" {
+		return errors.New("schemaVersion table and column were required")
+	}
+	return nil
+}
+
+// SchemaVersion represents schema version row condition, i.e. migration tool history table version
+type SchemaVersion struct {
+	Table  string
+	Column string
+	Value  string
+}
+
+// PingBackoff represents ping retry delay, the delay is multiplied after each failed attempt up to max delay
+type PingBackoff struct {
+	DelayMs    int     `description:"initial retry delay, 5000 by default"`
+	MaxDelayMs int     `description:"max retry delay, unlimited if 0"`
+	Multiplier float64 `description:"delay multiplier, 1 (fixed delay) by default"`
 }
 
 // PingResponse represents a ping response
 type PingResponse struct {
 	*BaseResponse
+	NotReady map[string]string `description:"last readiness error by datastore that was not ready before timeout"`
 }
 
 type SchemaTarget struct {
 	Datastore  string `description:"datastore"`
+	URL        string `description:"DDL script URL, CREATE TABLE, CREATE INDEX and ALTER TABLE ADD statements are used instead of datastore schema"`
+	Vendor     string `description:"DDL script vendor i.e. mysql, used for script lexing and vendor scoped mapping rules, datastore driver or target is used if empty"`
 	Target     string `description:"target vendor, use only if different than source"`
 	MappingURL string `description:"if target driver is used - you can provide data type mapping"`
 }
@@ -620,11 +1272,44 @@ type CheckSchemaRequest struct {
 	Tables           []string
 	CheckNullables   bool
 	CheckPrimaryKeys bool
+	CheckIndexes     bool         `description:"compare secondary indexes and unique constraints"`
+	CheckDefaults    bool         `description:"compare column defaults"`
+	CheckColumnOrder bool         `description:"compare column order"`
+	CheckForeignKeys bool         `description:"compare foreign keys"`
+	Rules            *SchemaRules `description:"equivalent types, ignored tables and columns, severity per difference kind"`
+	DestURL          string       `description:"if specified, DDL bringing dest schema in line with source is written there"`
+	DropTables       bool         `description:"flag to include DROP TABLE for dest tables missing in source"`
+}
+
+// Init initialises request
+func (r *CheckSchemaRequest) Init() error {
+	if r.Rules == nil {
+		r.Rules = &SchemaRules{}
+	}
+	return r.Rules.Init()
+}
+
+// Validate checks if request is valid
+func (r *CheckSchemaRequest) Validate() error {
+	if r.Source == nil || (r.Source.Datastore == "" && r.Source.URL == "") {
+		return errors.New("source datastore and URL were empty")
+	}
+	if r.Dest == nil || (r.Dest.Datastore == "" && r.Dest.URL == "") {
+		return errors.New("dest datastore and URL were empty")
+	}
+	if r.DestURL != "" && r.Dest.URL != "" {
+		return errors.New("migration DDL requires dest datastore, dest URL is not supported with destURL")
+	}
+	return nil
 }
 
+// SchemaTableCheck represents table schema check, error differences are also reported as validation failures
 type SchemaTableCheck struct {
 	Table string
 	*assertly.Validation
+	Differences []*SchemaDifference
+	Errors      int
+	Warnings    int
 }
 
 // CheckSchemaResponse represents schema check response
@@ -632,6 +1317,15 @@ type CheckSchemaResponse struct {
 	*BaseResponse
 	Tables []*SchemaTableCheck
 	*assertly.Validation
+	Errors    int
+	Warnings  int
+	Migration []string `description:"dest migration DDLs, generated when DestURL is specified"`
+	DestURL   string
+}
+
+// HasErrors returns true if any error severity difference was found
+func (r *CheckSchemaResponse) HasErrors() bool {
+	return r.Errors > 0
 }
 
 // NewCheckSchemaResponse returns new check schema response
@@ -0,0 +1,40 @@
+package dsunit_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"github.com/viant/dsunit"
+	"github.com/viant/dsunit/url"
+	"testing"
+)
+
+func TestFreezeRequest_TableName(t *testing.T) {
+	var useCases = []struct {
+		description string
+		request     *dsunit.FreezeRequest
+		expect      string
+	}{
+		{description: "explicit table", request: &dsunit.FreezeRequest{Table: "users", SQL: "SELECT * FROM v_users"}, expect: "users"},
+		{description: "SQL table", request: &dsunit.FreezeRequest{SQL: "SELECT id, name\nFROM db1.users WHERE id > 1"}, expect: "db1.users"},
+		{description: "no table", request: &dsunit.FreezeRequest{SQL: "SELECT 1"}, expect: ""},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, useCase.request.TableName(), useCase.description)
+	}
+}
+
+func TestInitRequest_Validate(t *testing.T) {
+	register := dsunit.NewRegisterRequest("db1", &dsc.Config{DriverName: "sqlite3"})
+	request := &dsunit.InitRequest{Datastore: "db1", RegisterRequest: register, MigrationsURL: "test/db1/migrations"}
+	assert.Nil(t, request.Validate())
+	request.RunScriptRequest = dsunit.NewRunScriptRequest("db1", url.NewResource("test/db1/schema.ddl"))
+	assert.NotNil(t, request.Validate())
+}
+
+func TestRunSQLRequest_Validate(t *testing.T) {
+	request := dsunit.NewRunSQLRequest("db1", "SELECT 1")
+	request.Expect = map[int][]map[string]interface{}{0: {{"a": 1}}}
+	assert.Nil(t, request.Validate())
+	request.Expect[1] = []map[string]interface{}{{"a": 1}}
+	assert.NotNil(t, request.Validate())
+}
@@ -0,0 +1,180 @@
+package dsunit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/assertly"
+	"github.com/viant/toolbox"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Discrepancy types
+const (
+	DiscrepancyMismatch = "mismatch"
+	DiscrepancyMissing  = "missing"
+	DiscrepancyExtra    = "extra"
+)
+
+// Discrepancy represents discrepant row, missing rows exist only in source1, extra rows only in source2
+type Discrepancy struct {
+	Type    string
+	Key     map[string]interface{}
+	Columns []string               `json:",omitempty"`
+	Source1 map[string]interface{} `json:",omitempty"`
+	Source2 map[string]interface{} `json:",omitempty"`
+}
+
+// discrepancyReport writes discrepancies as CSV or NDJSON, format is defined by URL extension
+type discrepancyReport struct {
+	indexBy []string
+	writer  io.WriteCloser
+	csv     *csv.Writer
+	encoder *json.Encoder
+}
+
+// write writes discrepancy, nil report ignores discrepancies
+func (r *discrepancyReport) write(discrepancy *Discrepancy) error {
+	if r == nil {
+		return nil
+	}
+	if r.encoder != nil {
+		return r.encoder.Encode(discrepancy)
+	}
+	var row = []string{discrepancy.Type}
+	for _, key := range r.indexBy {
+		row = append(row, toolbox.AsString(discrepancy.Key[key]))
+	}
+	row = append(row, strings.Join(discrepancy.Columns, ","))
+	for _, record := range []map[string]interface{}{discrepancy.Source1, discrepancy.Source2} {
+		var value string
+		if record != nil {
+			encoded, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			value = string(encoded)
+		}
+		row = append(row, value)
+	}
+	return r.csv.Write(row)
+}
+
+// Close flushes and closes report
+func (r *discrepancyReport) Close() error {
+	if r == nil {
+		return nil
+	}
+	if r.csv != nil {
+		r.csv.Flush()
+		if err := r.csv.Error(); err != nil {
+			_ = r.writer.Close()
+			return err
+		}
+	}
+	return r.writer.Close()
+}
+
+// newDiscrepancyReport creates discrepancy report for request DiscrepancyURL or nil if URL is empty
+func newDiscrepancyReport(request *CompareRequest) (*discrepancyReport, error) {
+	if request.DiscrepancyURL == "" {
+		return nil, nil
+	}
+	URL := url.Normalize(request.DiscrepancyURL, file.Scheme)
+	writer, err := afs.New().NewWriter(context.Background(), URL, file.DefaultFileOsMode)
+	if err != nil {
+		return nil, err
+	}
+	result := &discrepancyReport{indexBy: request.IndexBy(), writer: writer}
+	if !strings.HasSuffix(strings.ToLower(URL), ".csv") {
+		result.encoder = json.NewEncoder(writer)
+		return result, nil
+	}
+	result.csv = csv.NewWriter(writer)
+	header := append([]string{"type"}, result.indexBy...)
+	header = append(header, "columns", "source1", "source2")
+	if err = result.csv.Write(header); err != nil {
+		_ = writer.Close()
+		return nil, err
+	}
+	return result, nil
+}
+
+// reportRecord returns record copy without directives, byte slices are converted to text
+func reportRecord(record map[string]interface{}) map[string]interface{} {
+	var result = make(map[string]interface{}, len(record))
+	for k, v := range record {
+		if strings.HasPrefix(k, "@") {
+			continue
+		}
+		if bytes, ok := v.([]byte); ok {
+			v = string(bytes)
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// recordKey returns record index columns values
+func recordKey(indexBy []string, record map[string]interface{}) map[string]interface{} {
+	var result = make(map[string]interface{}, len(indexBy))
+	for _, key := range indexBy {
+		result[key] = record[key]
+	}
+	return result
+}
+
+// failedColumns returns sorted record columns referenced by assertion failure paths, the last column matched in a path wins
+func failedColumns(failures []*assertly.Failure, record1, record2 map[string]interface{}) []string {
+	var columns = make([]string, 0, len(record1)+len(record2))
+	for _, record := range []map[string]interface{}{record1, record2} {
+		for column := range record {
+			if !strings.HasPrefix(column, "@") {
+				columns = append(columns, column)
+			}
+		}
+	}
+	var unique = make(map[string]bool)
+	for _, failure := range failures {
+		matched, position := "", -1
+		for _, column := range columns {
+			if index := columnIndex(failure.Path, column); index > position || (index == position && len(column) > len(matched)) {
+				matched, position = column, index
+			}
+		}
+		if matched != "" && position != -1 {
+			unique[matched] = true
+		}
+	}
+	var result = make([]string, 0, len(unique))
+	for column := range unique {
+		result = append(result, column)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// columnIndex returns the last index of column name in path that is not a part of a longer name, or -1
+func columnIndex(path, column string) int {
+	for end := len(path); end > 0; {
+		index := strings.LastIndex(path[:end], column)
+		if index == -1 {
+			return -1
+		}
+		after := index + len(column)
+		if (index == 0 || !isNameByte(path[index-1])) && (after == len(path) || !isNameByte(path[after])) {
+			return index
+		}
+		end = index
+	}
+	return -1
+}
+
+func isNameByte(b byte) bool {
+	return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
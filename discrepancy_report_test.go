@@ -0,0 +1,59 @@
+package dsunit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/assertly"
+	"strings"
+	"testing"
+)
+
+type bufferCloser struct {
+	*bytes.Buffer
+}
+
+func (b *bufferCloser) Close() error {
+	return nil
+}
+
+func TestDiscrepancyReport_Write(t *testing.T) {
+	columns := failedColumns([]*assertly.Failure{
+		assertly.NewFailure("", "id:2.salary", "not equal", 10, 11),
+		assertly.NewFailure("", "id:2.active", "missing", true, nil),
+		assertly.NewFailure("", "id:2.salary", "not equal", 10, 11),
+	}, map[string]interface{}{"id": 2, "name": []byte("a"), "salary": 10, "@indexBy@": "id"}, map[string]interface{}{"id": 2, "name": "a", "salary": 11, "active": true})
+	discrepancy := &Discrepancy{
+		Type:    DiscrepancyMismatch,
+		Key:     map[string]interface{}{"id": 2},
+		Columns: columns,
+		Source1: reportRecord(map[string]interface{}{"id": 2, "name": []byte("a"), "@indexBy@": "id"}),
+		Source2: map[string]interface{}{"id": 2, "name": "b"},
+	}
+	assert.EqualValues(t, []string{"active", "salary"}, discrepancy.Columns)
+
+	buffer := &bufferCloser{Buffer: new(bytes.Buffer)}
+	report := &discrepancyReport{indexBy: []string{"id"}, writer: buffer, encoder: json.NewEncoder(buffer)}
+	assert.Nil(t, report.write(discrepancy))
+	assert.Nil(t, report.write(&Discrepancy{Type: DiscrepancyMissing, Key: map[string]interface{}{"id": 3}, Source1: map[string]interface{}{"id": 3}}))
+	assert.Nil(t, report.Close())
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if assert.Equal(t, 2, len(lines)) {
+		assert.Equal(t, `{"Type":"mismatch","Key":{"id":2},"Columns":["active","salary"],"Source1":{"id":2,"name":"a"},"Source2":{"id":2,"name":"b"}}`, lines[0])
+		assert.Equal(t, `{"Type":"missing","Key":{"id":3},"Source1":{"id":3}}`, lines[1])
+	}
+
+	buffer = &bufferCloser{Buffer: new(bytes.Buffer)}
+	report = &discrepancyReport{indexBy: []string{"id"}, writer: buffer, csv: csv.NewWriter(buffer)}
+	assert.Nil(t, report.write(discrepancy))
+	assert.Nil(t, report.write(&Discrepancy{Type: DiscrepancyExtra, Key: map[string]interface{}{"id": 4}, Source2: map[string]interface{}{"id": 4}}))
+	assert.Nil(t, report.Close())
+	assert.Equal(t, "mismatch,2,\"active,salary\",\"{\"\"id\"\":2,\"\"name\"\":\"\"a\"\"}\",\"{\"\"id\"\":2,\"\"name\"\":\"\"b\"\"}\"\nextra,4,,,\"{\"\"id\"\":4}\"\n", buffer.String())
+
+	assert.EqualValues(t, []string{"name_alias"}, failedColumns([]*assertly.Failure{assertly.NewFailure("", "[id:2].name_alias", "not equal", "a", "b")}, map[string]interface{}{"id": 2, "name": "a", "name_alias": "a"}, nil))
+
+	var nilReport *discrepancyReport
+	assert.Nil(t, nilReport.write(discrepancy))
+	assert.Nil(t, nilReport.Close())
+}
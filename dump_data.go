@@ -0,0 +1,126 @@
+package dsunit
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const timestampLiteralLayout = "2006-01-02 15:04:05.999999"
+
+// quote returns quoted and escaped string literal
+func (d *ddlDialect) quote(value string) string {
+	if d.backslashEscape {
+		value = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "\x00", `\0`).Replace(value)
+		return "'" + value + "'"
+	}
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
+
+// literal returns SQL literal for supplied value
+func (d *ddlDialect) literal(value interface{}) string {
+	switch actual := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if actual {
+			return "TRUE"
+		}
+		return "FALSE"
+	case float32:
+		return strconv.FormatFloat(float64(actual), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(actual, 'f', -1, 64)
+	case time.Time:
+		return fmt.Sprintf(d.timestampLiteral, d.quote(actual.Format(timestampLiteralLayout)))
+	case *time.Time:
+		if actual == nil {
+			return "NULL"
+		}
+		return d.literal(*actual)
+	case []byte:
+		if utf8.Valid(actual) {
+			return d.quote(string(actual))
+		}
+		return fmt.Sprintf(d.binaryLiteral, hex.EncodeToString(actual))
+	case string:
+		return d.quote(actual)
+	}
+	if toolbox.IsInt(value) {
+		return toolbox.AsString(value)
+	}
+	if reflected := reflect.ValueOf(value); reflected.Kind() == reflect.Ptr {
+		if reflected.IsNil() {
+			return "NULL"
+		}
+		return d.literal(reflected.Elem().Interface())
+	}
+	return d.quote(toolbox.AsString(value))
+}
+
+// insert returns INSERT statement for supplied rows of literals, multiple rows are only used if dialect supports it
+func (d *ddlDialect) insert(table string, columns []string, rows []string) string {
+	values := rows[0]
+	if len(rows) > 1 {
+		values = "\n\t" + strings.Join(rows, ",\n\t")
+	}
+	return fmt.Sprintf("INSERT INTO %v(%v) VALUES%v;", table, strings.Join(columns, ", "), values)
+}
+
+// dumpData returns tables rows as INSERT statements for request target dialect, tables are ordered so that referenced rows are inserted first
+func (s *service) dumpData(manager dsc.Manager, request *DumpRequest, tables []string) ([]string, error) {
+	target := request.Target
+	if target == "" {
+		target = manager.Config().DriverName
+	}
+	ddlDialect := getDDLDialect(target)
+	batchSize := request.BatchSize
+	if !ddlDialect.multiRowInsert {
+		batchSize = 1
+	}
+	var schemas = make([]*TableSchema, 0, len(tables))
+	for _, table := range tables {
+		tableSchema, err := s.tableSchema(manager, table, "", "")
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, tableSchema)
+	}
+	var result = make([]string, 0)
+	for _, table := range orderByForeignKeys(schemas) {
+		SQL := fmt.Sprintf("SELECT %v FROM %v", strings.Join(table.Columns, ", "), table.Table)
+		if criteria, ok := request.Criteria[table.Table]; ok && criteria != "" {
+			SQL += " WHERE " + criteria
+		}
+		var rows = make([]string, 0, batchSize)
+		err := manager.ReadAllWithHandler(SQL, nil, func(scanner dsc.Scanner) (bool, error) {
+			record := make(map[string]interface{})
+			if err := scanner.Scan(record); err != nil {
+				return false, err
+			}
+			var values = make([]string, 0, len(table.Columns))
+			for _, column := range table.Columns {
+				values = append(values, ddlDialect.literal(record[column]))
+			}
+			rows = append(rows, "("+strings.Join(values, ", ")+")")
+			if len(rows) >= batchSize {
+				result = append(result, ddlDialect.insert(table.Table, table.Columns, rows))
+				rows = rows[:0]
+			}
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) > 0 {
+			result = append(result, ddlDialect.insert(table.Table, table.Columns, rows))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+	"time"
+)
+
+func TestDDLDialect_Literal(t *testing.T) {
+	created := time.Date(2019, 3, 1, 10, 15, 0, 500000000, time.UTC)
+	name := "Bob"
+	var useCases = []struct {
+		description string
+		target      string
+		value       interface{}
+		expect      string
+	}{
+		{"null", "mysql", nil, "NULL"},
+		{"int", "mysql", int64(42), "42"},
+		{"float", "postgres", 12.5, "12.5"},
+		{"bool", "sqlite3", true, "TRUE"},
+		{"ansi quote", "postgres", "O'Brien", "'O''Brien'"},
+		{"mysql escape", "mysql", "O'Brien\\n\n", `'O\'Brien\\n\n'`},
+		{"bigquery escape", "bigquery", "a'b", `'a\'b'`},
+		{"text bytes", "mysql", []byte("abc"), "'abc'"},
+		{"binary mysql", "mysql", []byte{0xff, 0x01}, "X'ff01'"},
+		{"binary postgres", "postgres", []byte{0xff, 0x01}, "decode('ff01', 'hex')"},
+		{"binary bigquery", "bigquery", []byte{0xff, 0x01}, "FROM_HEX('ff01')"},
+		{"time", "mysql", created, "'2019-03-01 10:15:00.5'"},
+		{"time bigquery", "bigquery", &created, "TIMESTAMP '2019-03-01 10:15:00.5'"},
+		{"pointer", "postgres", &name, "'Bob'"},
+		{"nil pointer", "postgres", (*string)(nil), "NULL"},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, getDDLDialect(useCase.target).literal(useCase.value), useCase.description)
+	}
+}
+
+func TestDDLDialect_Insert(t *testing.T) {
+	columns := []string{"id", "name"}
+	assert.Equal(t, "INSERT INTO users(id, name) VALUES(1, 'a');", getDDLDialect("mysql").insert("users", columns, []string{"(1, 'a')"}))
+	assert.Equal(t, "INSERT INTO users(id, name) VALUES\n\t(1, 'a'),\n\t(2, 'b');", getDDLDialect("mysql").insert("users", columns, []string{"(1, 'a')", "(2, 'b')"}))
+}
+
+func TestOrderByForeignKeys(t *testing.T) {
+	orders := &TableSchema{TableDescriptor: &dsc.TableDescriptor{Table: "orders"}, ForeignKeys: []*ForeignKey{{Table: "orders", RefTable: "users"}}}
+	lines := &TableSchema{TableDescriptor: &dsc.TableDescriptor{Table: "order_lines"}, ForeignKeys: []*ForeignKey{{Table: "order_lines", RefTable: "orders"}, {Table: "order_lines", RefTable: "products"}}}
+	users := &TableSchema{TableDescriptor: &dsc.TableDescriptor{Table: "users"}}
+	var names = make([]string, 0)
+	for _, table := range orderByForeignKeys([]*TableSchema{lines, orders, users}) {
+		names = append(names, table.Table)
+	}
+	assert.EqualValues(t, []string{"users", "orders", "order_lines"}, names)
+}
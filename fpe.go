@@ -0,0 +1,97 @@
+package dsunit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"github.com/viant/scy/kms"
+	"strconv"
+)
+
+const fpeKeySeed = "dsunit/fpe"
+
+// formatPreservingCipher represents a deterministic cipher that keeps value length and character class:
+// digits stay digits, lower/upper case letters keep their case, any other character is left intact.
+// Each character shift depends on the column (tweak), position and preceding plain text, so it can be reversed left to right.
+type formatPreservingCipher struct {
+	key []byte
+}
+
+// Encrypt encrypts supplied value
+func (c *formatPreservingCipher) Encrypt(tweak, value string) string {
+	runes := []rune(value)
+	var plain = make([]rune, 0, len(runes))
+	for i, r := range runes {
+		runes[i] = shiftRune(r, c.shift(tweak, len(runes), i, plain))
+		plain = append(plain, r)
+	}
+	return string(runes)
+}
+
+// Decrypt decrypts supplied value
+func (c *formatPreservingCipher) Decrypt(tweak, value string) string {
+	runes := []rune(value)
+	var plain = make([]rune, 0, len(runes))
+	for i, r := range runes {
+		runes[i] = shiftRune(r, -c.shift(tweak, len(runes), i, plain))
+		plain = append(plain, runes[i])
+	}
+	return string(runes)
+}
+
+func (c *formatPreservingCipher) shift(tweak string, size, position int, plain []rune) int {
+	mac := hmac.New(sha256.New, c.key)
+	_, _ = mac.Write([]byte(tweak))
+	_, _ = mac.Write([]byte{0})
+	_, _ = mac.Write([]byte(strconv.Itoa(size) + ":" + strconv.Itoa(position)))
+	_, _ = mac.Write([]byte{0})
+	_, _ = mac.Write([]byte(string(plain)))
+	sum := mac.Sum(nil)
+	return int(sum[0])<<8 | int(sum[1])
+}
+
+func shiftRune(r rune, shift int) rune {
+	switch {
+	case r >= '0' && r <= '9':
+		return rotateRune(r, '0', 10, shift)
+	case r >= 'a' && r <= 'z':
+		return rotateRune(r, 'a', 26, shift)
+	case r >= 'A' && r <= 'Z':
+		return rotateRune(r, 'A', 26, shift)
+	}
+	return r
+}
+
+func rotateRune(r, base rune, size, shift int) rune {
+	offset := (int(r-base) + shift) % size
+	if offset < 0 {
+		offset += size
+	}
+	return base + rune(offset)
+}
+
+// newFormatPreservingCipher creates a cipher with a key derived from supplied kms key, kms cipher has to be deterministic
+func newFormatPreservingCipher(ctx context.Context, key *kms.Key) (*formatPreservingCipher, error) {
+	if key == nil {
+		return nil, fmt.Errorf("fpe key was empty")
+	}
+	cipher, err := kms.Lookup(key.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := cipher.Encrypt(ctx, key, []byte(fpeKeySeed))
+	if err != nil {
+		return nil, err
+	}
+	control, err := cipher.Encrypt(ctx, key, []byte(fpeKeySeed))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(derived, control) {
+		return nil, fmt.Errorf("unable to derive fpe key: %v cipher is not deterministic", key.Scheme)
+	}
+	digest := sha256.Sum256(derived)
+	return &formatPreservingCipher{key: digest[:]}, nil
+}
@@ -0,0 +1,49 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFormatPreservingCipher(t *testing.T) {
+	cipher := &formatPreservingCipher{key: []byte("test key")}
+	var useCases = []struct {
+		description string
+		column      string
+		value       string
+	}{
+		{description: "digits", column: "phone", value: "4155550123"},
+		{description: "email", column: "email", value: "John.Doe42@example.com"},
+		{description: "formatted", column: "ssn", value: "123-45-6789"},
+		{description: "empty", column: "name", value: ""},
+	}
+	for _, useCase := range useCases {
+		encrypted := cipher.Encrypt(useCase.column, useCase.value)
+		assert.Equal(t, len(useCase.value), len(encrypted), useCase.description)
+		for i := range useCase.value {
+			expected, actual := useCase.value[i], encrypted[i]
+			switch {
+			case expected >= '0' && expected <= '9':
+				assert.True(t, actual >= '0' && actual <= '9', useCase.description)
+			case expected >= 'a' && expected <= 'z':
+				assert.True(t, actual >= 'a' && actual <= 'z', useCase.description)
+			case expected >= 'A' && expected <= 'Z':
+				assert.True(t, actual >= 'A' && actual <= 'Z', useCase.description)
+			default:
+				assert.Equal(t, expected, actual, useCase.description)
+			}
+		}
+		assert.Equal(t, encrypted, cipher.Encrypt(useCase.column, useCase.value), useCase.description)
+		assert.Equal(t, useCase.value, cipher.Decrypt(useCase.column, encrypted), useCase.description)
+	}
+	assert.NotEqual(t, cipher.Encrypt("phone", "4155550123"), cipher.Encrypt("mobile", "4155550123"))
+}
+
+func TestIsExpressionValue(t *testing.T) {
+	for _, value := range []string{"<ds:sha1[\"abc\"]>", "~/^41[0-9]+$/", "@indexBy@"} {
+		assert.True(t, isExpressionValue(value), value)
+	}
+	for _, value := range []string{"/home/user", "!important", "@", "john@acme.com", "4155550123"} {
+		assert.False(t, isExpressionValue(value), value)
+	}
+}
@@ -212,12 +212,17 @@ func discoverBaseURLAndPrefix(operation string) (string, string) {
 	testfile, method, _ := toolbox.DiscoverCaller(2, 10, "tester.go", "helper.go", "static.go")
 	parent, name := path.Split(testfile)
 	name = string(name[:len(name)-3]) //remove .go
+	return parent, datasetFilePrefix(name, method, operation)
+}
+
+//datasetFilePrefix returns dataset file prefix for supplied test file name (without .go), test method and operation
+func datasetFilePrefix(name, method, operation string) string {
 	var lastSegment = strings.LastIndex(method, "_")
 	if lastSegment > 0 {
 		method = string(method[lastSegment+1:])
 	}
 	method = convertToLowerUnderscore(method)
-	return parent, fmt.Sprintf(name+"_%v_%v_", method, operation)
+	return fmt.Sprintf(name+"_%v_%v_", method, operation)
 }
 
 func escapeVariableIfNeeded(val string) string {
@@ -0,0 +1,216 @@
+package dsunit
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/viant/afs"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/url"
+	"github.com/viant/dsc"
+	"github.com/viant/dsunit/script"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	flywayMigrationExpr      = regexp.MustCompile(`^[Vv](\d+(?:[._]\d+)*)__(.*)\.sql$`)
+	golangMigrationExpr      = regexp.MustCompile(`^(\d+)_(.*)\.(up|down)\.sql$`)
+	versionedMigrationExpr   = regexp.MustCompile(`^(\d+(?:\.\d+)*)[_-](.*)\.sql$`)
+	migrationVersionSplitter = regexp.MustCompile(`[._]`)
+)
+
+// migrationScript represents migration with its script content and numeric version segments
+type migrationScript struct {
+	*Migration
+	URL      string
+	content  string
+	segments []int
+}
+
+// newMigrationScript parses migration script name, it returns nil for not versioned and golang-migrate down scripts
+func newMigrationScript(name string) *migrationScript {
+	var version, description string
+	if matched := flywayMigrationExpr.FindStringSubmatch(name); matched != nil {
+		version, description = matched[1], matched[2]
+	} else if matched := golangMigrationExpr.FindStringSubmatch(name); matched != nil {
+		if strings.ToLower(matched[3]) == "down" {
+			return nil
+		}
+		version, description = matched[1], matched[2]
+	} else if matched := versionedMigrationExpr.FindStringSubmatch(name); matched != nil {
+		version, description = matched[1], matched[2]
+	} else {
+		return nil
+	}
+	result := &migrationScript{Migration: &Migration{Script: name, Description: strings.Replace(description, "_", " ", -1)}}
+	result.segments = versionSegments(version)
+	var parts = make([]string, 0, len(result.segments))
+	for _, value := range result.segments {
+		parts = append(parts, strconv.Itoa(value))
+	}
+	result.Version = strings.Join(parts, ".")
+	return result
+}
+
+// versionSegments returns numeric segments of version separated by . or _
+func versionSegments(version string) []int {
+	var result = make([]int, 0)
+	for _, segment := range migrationVersionSplitter.Split(version, -1) {
+		value, _ := strconv.Atoi(segment)
+		result = append(result, value)
+	}
+	return result
+}
+
+// compareVersions returns negative, zero or positive number if version segments are lower, equal or greater
+func compareVersions(segments1, segments2 []int) int {
+	for i := 0; i < len(segments1) || i < len(segments2); i++ {
+		var value1, value2 int
+		if i < len(segments1) {
+			value1 = segments1[i]
+		}
+		if i < len(segments2) {
+			value2 = segments2[i]
+		}
+		if value1 != value2 {
+			return value1 - value2
+		}
+	}
+	return 0
+}
+
+// readMigrationScripts returns migration scripts ordered by version, other files are ignored
+func readMigrationScripts(URL string) ([]*migrationScript, error) {
+	var storageService = afs.New()
+	var ctx = context.Background()
+	objects, err := storageService.List(ctx, URL, option.NewRecursive(false))
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*migrationScript, 0)
+	var scripts = make(map[string]string)
+	for _, object := range objects {
+		if object.IsDir() {
+			continue
+		}
+		migration := newMigrationScript(object.Name())
+		if migration == nil {
+			continue
+		}
+		if previous, ok := scripts[migration.Version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %v: %v, %v", migration.Version, previous, migration.Script)
+		}
+		scripts[migration.Version] = migration.Script
+		migration.URL = url.Join(URL, object.Name())
+		data, err := storageService.Download(ctx, object)
+		if err != nil {
+			return nil, err
+		}
+		migration.content = string(data)
+		checksum := md5.Sum(data)
+		migration.Checksum = hex.EncodeToString(checksum[:])
+		result = append(result, migration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return compareVersions(result[i].segments, result[j].segments) < 0
+	})
+	return result, nil
+}
+
+// readAppliedMigrations returns applied migrations checksum by version, tracking table is created if needed
+func readAppliedMigrations(manager dsc.Manager, table string) (map[string]string, error) {
+	var result = make(map[string]string)
+	if probeTable(manager, table) != nil {
+		DDL := fmt.Sprintf("CREATE TABLE %v (version VARCHAR(64) NOT NULL PRIMARY KEY, description VARCHAR(255), script VARCHAR(255), checksum VARCHAR(64), applied_at TIMESTAMP)", table)
+		if _, err := manager.Execute(DDL); err != nil {
+			return nil, fmt.Errorf("failed to create %v: %v", table, err)
+		}
+		return result, nil
+	}
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, fmt.Sprintf("SELECT version, checksum FROM %v", table), nil, nil); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		result[textValue(record["version"])] = textValue(record["checksum"])
+	}
+	return result, nil
+}
+
+// recordMigration inserts applied migration into tracking table on supplied connection, so that it shares migration script transaction
+func recordMigration(manager dsc.Manager, connection dsc.Connection, table string, migration *Migration) error {
+	dialect := getDDLDialect(manager.Config().DriverName)
+	DML := fmt.Sprintf("INSERT INTO %v(version, description, script, checksum, applied_at) VALUES(%v, %v, %v, %v, CURRENT_TIMESTAMP)", table,
+		dialect.literal(migration.Version), dialect.literal(migration.Description), dialect.literal(migration.Script), dialect.literal(migration.Checksum))
+	_, err := manager.ExecuteOnConnection(connection, DML, nil)
+	return err
+}
+
+// migrate runs pending migration scripts in version order, applied scripts checksum drift stops migration
+func (s *service) migrate(request *MigrateRequest, manager dsc.Manager, response *MigrateResponse) error {
+	migrations, err := readMigrationScripts(request.URL)
+	if err != nil {
+		return err
+	}
+	applied, err := readAppliedMigrations(manager, request.Table)
+	if err != nil {
+		return err
+	}
+	var latestVersion string
+	var latest []int
+	for version := range applied {
+		if segments := versionSegments(version); compareVersions(segments, latest) > 0 {
+			latest, latestVersion = segments, version
+		}
+	}
+	var pending = make([]*migrationScript, 0)
+	for _, migration := range migrations {
+		checksum, ok := applied[migration.Version]
+		if !ok {
+			pending = append(pending, migration)
+			continue
+		}
+		response.Version = migration.Version
+		if checksum != migration.Checksum {
+			response.Drifted = append(response.Drifted, migration.Migration)
+		}
+	}
+	if len(response.Drifted) > 0 {
+		var scripts = make([]string, 0, len(response.Drifted))
+		for _, migration := range response.Drifted {
+			scripts = append(scripts, migration.Script)
+		}
+		return fmt.Errorf("applied migration checksum changed: %v", strings.Join(scripts, ", "))
+	}
+	for _, migration := range pending {
+		if compareVersions(migration.segments, latest) < 0 {
+			return fmt.Errorf("pending migration %v version %v is lower than the latest applied version %v", migration.Script, migration.Version, latestVersion)
+		}
+	}
+	dialect := script.DialectForDriver(manager.Config().DriverName)
+	scriptRequest := &RunScriptRequest{Datastore: request.Datastore, Expand: request.Expand, Transactional: request.Transactional}
+	for _, migration := range pending {
+		var statements = make([]*scriptStatement, 0)
+		for _, statement := range script.ParseStatements(migration.content, dialect) {
+			statements = append(statements, &scriptStatement{URL: migration.URL, Statement: statement})
+		}
+		scriptResponse := &RunSQLResponse{BaseResponse: NewBaseOkResponse()}
+		err = s.runScript(scriptRequest, manager, statements, scriptResponse, func(connection dsc.Connection) error {
+			if err := recordMigration(manager, connection, request.Table, migration.Migration); err != nil {
+				return fmt.Errorf("failed to record migration %v: %v", migration.Script, err)
+			}
+			return nil
+		})
+		if err != nil {
+			response.Statements = scriptResponse.Statements
+			return err
+		}
+		response.Applied = append(response.Applied, migration.Migration)
+		response.Version = migration.Version
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewMigrationScript(t *testing.T) {
+	var useCases = []struct {
+		name        string
+		version     string
+		description string
+		skipped     bool
+	}{
+		{name: "V1__create_users.sql", version: "1", description: "create users"},
+		{name: "V1_2__add_index.sql", version: "1.2", description: "add index"},
+		{name: "v2.1__seed.sql", version: "2.1", description: "seed"},
+		{name: "0003_add_orders.up.sql", version: "3", description: "add orders"},
+		{name: "0003_add_orders.down.sql", skipped: true},
+		{name: "004-add-products.sql", version: "4", description: "add-products"},
+		{name: "4.1_alter_products.sql", version: "4.1", description: "alter products"},
+		{name: "README.md", skipped: true},
+		{name: "seed.sql", skipped: true},
+	}
+	for _, useCase := range useCases {
+		actual := newMigrationScript(useCase.name)
+		if useCase.skipped {
+			assert.Nil(t, actual, useCase.name)
+			continue
+		}
+		if !assert.NotNil(t, actual, useCase.name) {
+			continue
+		}
+		assert.Equal(t, useCase.version, actual.Version, useCase.name)
+		assert.Equal(t, useCase.description, actual.Description, useCase.name)
+		assert.Equal(t, useCase.name, actual.Script, useCase.name)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.True(t, compareVersions([]int{1, 2}, []int{1, 10}) < 0)
+	assert.True(t, compareVersions([]int{2}, []int{1, 10}) > 0)
+	assert.Equal(t, 0, compareVersions([]int{1}, []int{1, 0}))
+}
+
+func TestVersionSegments(t *testing.T) {
+	assert.EqualValues(t, []int{1, 2}, versionSegments("1.2"))
+	assert.EqualValues(t, []int{3}, versionSegments("0003"))
+}
@@ -19,6 +19,8 @@ const (
 	ObfuscationMethodShuffle    = "shuffle"
 	ObfuscationMethodDictionary = "dictionary"
 	ObfuscationMethodCipher     = "cipher"
+	//ObfuscationMethodFPE format preserving encryption, keeps value length and character class (digits stay digits)
+	ObfuscationMethodFPE = "fpe"
 )
 
 type Obfuscation struct {
@@ -29,10 +31,11 @@ type Obfuscation struct {
 	Key           *kms.Key
 	IDKey         string
 	Template      string
+	fpe           *formatPreservingCipher
 }
 
 func (o *Obfuscation) Init(ctx context.Context) {
-	if o.Method == ObfuscationMethodCipher {
+	if o.Method == ObfuscationMethodCipher || o.Method == ObfuscationMethodFPE {
 		if o.Key == nil {
 			if o.Key == nil {
 				o.Key = &kms.Key{}
@@ -95,11 +98,59 @@ func (o *Obfuscation) Obfuscate(ctx context.Context, value string, record map[st
 			return "", err
 		}
 		return base64.StdEncoding.EncodeToString(enc), nil
+	case ObfuscationMethodFPE:
+		fpe, err := o.formatPreservingCipher(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fpe.Encrypt(column, value), nil
 	default:
 		return "", fmt.Errorf("unsupported obfuscation method:%v", o.Method)
 	}
 }
 
+// IsReversible returns true if obfuscated value can be decrypted
+func (o *Obfuscation) IsReversible() bool {
+	return o.Method == ObfuscationMethodCipher || o.Method == ObfuscationMethodFPE
+}
+
+// Decrypt reverses cipher or fpe obfuscation
+func (o *Obfuscation) Decrypt(ctx context.Context, value string, column string) (string, error) {
+	switch o.Method {
+	case ObfuscationMethodCipher:
+		cipher, err := kms.Lookup(o.Key.Scheme)
+		if err != nil {
+			return "", err
+		}
+		enc, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return "", err
+		}
+		dec, err := cipher.Decrypt(ctx, o.Key, enc)
+		if err != nil {
+			return "", err
+		}
+		return string(dec), nil
+	case ObfuscationMethodFPE:
+		fpe, err := o.formatPreservingCipher(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fpe.Decrypt(column, value), nil
+	default:
+		return "", fmt.Errorf("obfuscation method %v is not reversible", o.Method)
+	}
+}
+
+func (o *Obfuscation) formatPreservingCipher(ctx context.Context) (*formatPreservingCipher, error) {
+	if o.fpe != nil {
+		return o.fpe, nil
+	}
+	var err error
+	o.fpe, err = newFormatPreservingCipher(ctx, o.Key)
+	return o.fpe, err
+}
+
 func (o *Obfuscation) randDictionaryValue() string {
 	rand.Seed(time.Now().UnixNano())
 	index := int(rand.Int31()) % len(o.Dictionary)
@@ -0,0 +1,243 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/toolbox"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PII kinds
+const (
+	PIIEmail      = "email"
+	PIIPhone      = "phone"
+	PIICardNumber = "cardNumber"
+	PIINationalID = "nationalId"
+	PIIName       = "name"
+	PIIIPAddress  = "ipAddress"
+)
+
+// PIIScan represents PII scan options
+type PIIScan struct {
+	Strict bool     `description:"fail freeze when unmasked PII is detected"`
+	Ignore []string `description:"columns excluded from PII scan"`
+}
+
+// PIIColumn represents a column suspected to hold PII
+type PIIColumn struct {
+	Column      string
+	Kind        string
+	NameMatched bool `description:"column name suggests PII"`
+	Matched     int  `description:"values matching PII pattern"`
+	Sampled     int  `description:"non empty values inspected"`
+}
+
+// PIIReport represents PII scan report
+type PIIReport struct {
+	Records     int
+	Columns     []*PIIColumn
+	Obfuscation []Obfuscation `description:"suggested obfuscation rules, ready to use with FreezeRequest.Obfuscation"`
+}
+
+// HasPII returns true if any suspicious column was detected
+func (r *PIIReport) HasPII() bool {
+	return r != nil && len(r.Columns) > 0
+}
+
+// Summary returns suspicious columns summary
+func (r *PIIReport) Summary() string {
+	var items = make([]string, 0)
+	for _, column := range r.Columns {
+		items = append(items, fmt.Sprintf("%v(%v: %v/%v)", column.Column, column.Kind, column.Matched, column.Sampled))
+	}
+	return strings.Join(items, ", ")
+}
+
+type piiDetector struct {
+	kind        string
+	columnName  *regexp.Regexp
+	matchValue  func(value string) bool
+	obfuscation Obfuscation
+}
+
+var emailExpr = regexp.MustCompile(`^[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}$`)
+var nationalIDExpr = regexp.MustCompile(`^\d{3}-\d{2}-\d{4}$`)
+
+var piiDetectors = []*piiDetector{
+	{
+		kind:        PIIEmail,
+		columnName:  regexp.MustCompile(`(^|_)e?_?mail(_?address)?($|_)`),
+		matchValue:  emailExpr.MatchString,
+		obfuscation: Obfuscation{Method: ObfuscationMethodReplace, Template: "%s_%v@example.com"},
+	},
+	{
+		kind:        PIICardNumber,
+		columnName:  regexp.MustCompile(`(^|_)(card|cc|credit_?card|pan)(_?(number|num|no))?($|_)`),
+		matchValue:  isCardNumber,
+		obfuscation: Obfuscation{Method: ObfuscationMethodFPE},
+	},
+	{
+		kind:        PIINationalID,
+		columnName:  regexp.MustCompile(`(^|_)(ssn|sin|nin|national_?id|passport(_?(number|no))?|tax_?id|social_?security(_?number)?)($|_)`),
+		matchValue:  nationalIDExpr.MatchString,
+		obfuscation: Obfuscation{Method: ObfuscationMethodFPE},
+	},
+	{
+		kind:        PIIPhone,
+		columnName:  regexp.MustCompile(`(^|_)(phone|mobile|cell|msisdn|fax|tel|telephone)(_?(number|num|no))?($|_)`),
+		matchValue:  isPhoneNumber,
+		obfuscation: Obfuscation{Method: ObfuscationMethodFPE},
+	},
+	{
+		kind:        PIIIPAddress,
+		columnName:  regexp.MustCompile(`(^|_)(ip|ip_?address|remote_?addr(ess)?|client_?ip)($|_)`),
+		matchValue:  isIPAddress,
+		obfuscation: Obfuscation{Method: ObfuscationMethodFPE},
+	},
+	{
+		kind:        PIIName,
+		columnName:  regexp.MustCompile(`(^|_)(first|last|middle|full|given|family|maiden|customer|contact|person|user)_?name($|_)|(^|_)surname($|_)`),
+		obfuscation: Obfuscation{Method: ObfuscationMethodReplace, Template: "%s_%v"},
+	},
+}
+
+// isCardNumber returns true for 13-19 digits number with valid Luhn checksum
+func isCardNumber(value string) bool {
+	digits := make([]int, 0, len(value))
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, int(r-'0'))
+		case r == ' ' || r == '-':
+		default:
+			return false
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := digits[i]
+		if (len(digits)-i)%2 == 0 {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+// isPhoneNumber returns true for formatted phone numbers i.e. +1 (415) 555-0123
+func isPhoneNumber(value string) bool {
+	digits, formatted := 0, strings.HasPrefix(value, "+")
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case strings.ContainsRune("-(). ", r):
+			formatted = true
+		case r == '+':
+		default:
+			return false
+		}
+	}
+	return formatted && digits >= 10 && digits <= 15
+}
+
+func isIPAddress(value string) bool {
+	if !strings.Contains(value, ".") && !strings.Contains(value, ":") {
+		return false
+	}
+	return net.ParseIP(value) != nil
+}
+
+// matchPIIColumnName returns detector matching column name, camel case and upper case names are normalized first
+func matchPIIColumnName(column string) *piiDetector {
+	name := strings.ToLower(column)
+	if strings.ToUpper(column) != column {
+		name = convertToLowerUnderscore(column)
+	}
+	name = strings.Replace(strings.Replace(name, "-", "_", -1), " ", "_", -1)
+	for _, detector := range piiDetectors {
+		if detector.columnName.MatchString(name) {
+			return detector
+		}
+	}
+	return nil
+}
+
+// scanPII inspects records and column names for likely PII, masked and ignored columns are skipped
+func scanPII(records []map[string]interface{}, masked map[string]bool) *PIIReport {
+	var result = &PIIReport{Records: len(records), Columns: make([]*PIIColumn, 0)}
+	var columns = make(map[string]*PIIColumn)
+	var sampled = make(map[string]int)
+	var matched = make(map[string]map[*piiDetector]int)
+
+	for _, record := range records {
+		for column, value := range record {
+			if masked[column] || strings.HasPrefix(column, "@") || value == nil {
+				continue
+			}
+			text := strings.TrimSpace(toolbox.AsString(value))
+			if text == "" {
+				continue
+			}
+			sampled[column]++
+			for _, detector := range piiDetectors {
+				if detector.matchValue == nil || !detector.matchValue(text) {
+					continue
+				}
+				if _, ok := matched[column]; !ok {
+					matched[column] = make(map[*piiDetector]int)
+				}
+				matched[column][detector]++
+				break
+			}
+		}
+	}
+
+	var detectors = make(map[string]*piiDetector)
+	for column, count := range sampled {
+		detector := matchPIIColumnName(column)
+		nameMatched := detector != nil
+		matchedCount := 0
+		if nameMatched {
+			matchedCount = matched[column][detector]
+		} else {
+			for _, candidate := range piiDetectors {
+				if candidateCount := matched[column][candidate]; candidateCount > matchedCount {
+					detector, matchedCount = candidate, candidateCount
+				}
+			}
+		}
+		if detector == nil {
+			continue
+		}
+		columns[column] = &PIIColumn{Column: column, Kind: detector.kind, NameMatched: nameMatched, Matched: matchedCount, Sampled: count}
+		detectors[column] = detector
+	}
+
+	var names = make([]string, 0, len(columns))
+	for column := range columns {
+		names = append(names, column)
+	}
+	sort.Strings(names)
+	var rules = make(map[string]int)
+	for _, column := range names {
+		result.Columns = append(result.Columns, columns[column])
+		suggestion := detectors[column].obfuscation
+		key := string(suggestion.Method) + "/" + suggestion.Template
+		index, ok := rules[key]
+		if !ok {
+			index = len(result.Obfuscation)
+			rules[key] = index
+			result.Obfuscation = append(result.Obfuscation, Obfuscation{Method: suggestion.Method, Template: suggestion.Template})
+		}
+		result.Obfuscation[index].Columns = append(result.Obfuscation[index].Columns, column)
+	}
+	return result
+}
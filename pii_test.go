@@ -0,0 +1,47 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScanPII(t *testing.T) {
+	records := []map[string]interface{}{
+		{"id": 1, "contact": "john@acme.com", "mobileNumber": "4155550123", "card": "4111 1111 1111 1111", "client_ip": "10.1.2.3", "LAST_NAME": "Doe", "ssn": "123-45-6789", "comments": "abc"},
+		{"id": 2, "contact": "+1 (415) 555-0199", "mobileNumber": "4155550124", "card": "4111 1111 1111 1112", "client_ip": "::1", "LAST_NAME": "Smith", "ssn": "987-65-4321", "comments": "def"},
+		{"id": 3, "contact": "jane@acme.com", "email": "masked", "comments": nil},
+	}
+	report := scanPII(records, map[string]bool{"email": true})
+	assert.True(t, report.HasPII())
+	assert.Equal(t, 3, report.Records)
+
+	var kinds = make(map[string]string)
+	for _, column := range report.Columns {
+		kinds[column.Column] = column.Kind
+	}
+	assert.EqualValues(t, map[string]string{
+		"LAST_NAME":    PIIName,
+		"card":         PIICardNumber,
+		"client_ip":    PIIIPAddress,
+		"contact":      PIIEmail,
+		"mobileNumber": PIIPhone,
+		"ssn":          PIINationalID,
+	}, kinds)
+
+	assert.Equal(t, "LAST_NAME", report.Columns[0].Column)
+	assert.True(t, report.Columns[0].NameMatched)
+	assert.Equal(t, 0, report.Columns[0].Matched)
+	assert.Equal(t, "card", report.Columns[1].Column)
+	assert.Equal(t, 1, report.Columns[1].Matched)
+	assert.Equal(t, 2, report.Columns[1].Sampled)
+	assert.Equal(t, "contact", report.Columns[3].Column)
+	assert.False(t, report.Columns[3].NameMatched)
+	assert.Equal(t, 2, report.Columns[3].Matched)
+
+	var suggested = make(map[string][]string)
+	for _, rule := range report.Obfuscation {
+		suggested[string(rule.Method)] = append(suggested[string(rule.Method)], rule.Columns...)
+	}
+	assert.EqualValues(t, []string{"card", "client_ip", "mobileNumber", "ssn"}, suggested[ObfuscationMethodFPE])
+	assert.EqualValues(t, []string{"LAST_NAME", "contact"}, suggested[ObfuscationMethodReplace])
+}
@@ -0,0 +1,131 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"sort"
+	"sync"
+	"time"
+)
+
+const readinessCountColumn = "dsunit_count"
+
+// delay returns retry delay for supplied zero based attempt
+func (b *PingBackoff) delay(attempt int) time.Duration {
+	delay := float64(b.DelayMs)
+	for i := 0; i < attempt; i++ {
+		delay *= b.Multiplier
+		if b.MaxDelayMs > 0 && delay >= float64(b.MaxDelayMs) {
+			delay = float64(b.MaxDelayMs)
+			break
+		}
+	}
+	return time.Duration(delay) * time.Millisecond
+}
+
+// readCount returns count column value of supplied count SQL
+func readCount(manager dsc.Manager, SQL string, params ...interface{}) (int, error) {
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, SQL, params, nil); err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	return toolbox.AsInt(records[0][readinessCountColumn]), nil
+}
+
+// probeTable returns an error if table can not be queried
+func probeTable(manager dsc.Manager, table string) error {
+	var records = make([]map[string]interface{}, 0)
+	return manager.ReadAll(&records, fmt.Sprintf("SELECT 1 FROM %v WHERE 1 = 0", table), nil, nil)
+}
+
+// checkReadiness returns nil if datastore is online and satisfies readiness conditions
+func checkReadiness(manager dsc.Manager, readiness *Readiness) error {
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	if err := dialect.Ping(manager); err != nil {
+		return err
+	}
+	if readiness == nil {
+		return nil
+	}
+	if readiness.SQL != "" {
+		var records = make([]map[string]interface{}, 0)
+		if err := manager.ReadAll(&records, readiness.SQL, nil, nil); err != nil {
+			return fmt.Errorf("probe SQL failed: %v", err)
+		}
+	}
+	for _, table := range readiness.Tables {
+		if err := probeTable(manager, table); err != nil {
+			return fmt.Errorf("table %v was not found: %v", table, err)
+		}
+	}
+	var tables = make([]string, 0, len(readiness.MinRowCount))
+	for table := range readiness.MinRowCount {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		count, err := readCount(manager, fmt.Sprintf("SELECT COUNT(*) AS %v FROM %v", readinessCountColumn, table))
+		if err != nil {
+			return fmt.Errorf("failed to count %v rows: %v", table, err)
+		}
+		if minCount := readiness.MinRowCount[table]; count < minCount {
+			return fmt.Errorf("table %v has %v rows, expected at least %v", table, count, minCount)
+		}
+	}
+	if version := readiness.SchemaVersion; version != nil {
+		SQL := fmt.Sprintf("SELECT COUNT(*) AS %v FROM %v WHERE %v = ?", readinessCountColumn, version.Table, version.Column)
+		count, err := readCount(manager, SQL, version.Value)
+		if err != nil {
+			return fmt.Errorf("failed to read schema version: %v", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("schema version %v.%v = %v was not found", version.Table, version.Column, version.Value)
+		}
+	}
+	return nil
+}
+
+// waitForReadiness checks datastore readiness with backoff until it is ready or timeout, backoff delay never exceeds the remaining time, it returns the last readiness error
+func waitForReadiness(manager dsc.Manager, readiness *Readiness, backoff *PingBackoff, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = checkReadiness(manager, readiness); err == nil {
+			break
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		delay := backoff.delay(attempt)
+		if delay > remaining {
+			delay = remaining
+		}
+		time.Sleep(delay)
+	}
+	return err
+}
+
+// pingDatastores waits concurrently for datastores readiness, it returns readiness error by not ready datastore
+func (s *service) pingDatastores(readiness map[string]*Readiness, backoff *PingBackoff, timeout time.Duration) map[string]string {
+	var result = make(map[string]string)
+	var mux = &sync.Mutex{}
+	waitGroup := &sync.WaitGroup{}
+	for datastore := range readiness {
+		waitGroup.Add(1)
+		go func(datastore string) {
+			defer waitGroup.Done()
+			if err := waitForReadiness(s.registry.Get(datastore), readiness[datastore], backoff, timeout); err != nil {
+				mux.Lock()
+				result[datastore] = err.Error()
+				mux.Unlock()
+			}
+		}(datastore)
+	}
+	waitGroup.Wait()
+	return result
+}
@@ -0,0 +1,46 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPingBackoff_Delay(t *testing.T) {
+	var useCases = []struct {
+		description string
+		backoff     *PingBackoff
+		expect      []time.Duration
+	}{
+		{
+			description: "fixed delay",
+			backoff:     &PingBackoff{DelayMs: 5000, Multiplier: 1},
+			expect:      []time.Duration{5 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+		{
+			description: "exponential delay",
+			backoff:     &PingBackoff{DelayMs: 100, Multiplier: 2},
+			expect:      []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond},
+		},
+		{
+			description: "max delay",
+			backoff:     &PingBackoff{DelayMs: 100, Multiplier: 3, MaxDelayMs: 500},
+			expect:      []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond},
+		},
+	}
+	for _, useCase := range useCases {
+		for attempt, expect := range useCase.expect {
+			assert.Equal(t, expect, useCase.backoff.delay(attempt), useCase.description)
+		}
+	}
+}
+
+func TestPingRequest_Init(t *testing.T) {
+	request := &PingRequest{Datastore: "db1"}
+	assert.Nil(t, request.Init())
+	assert.Equal(t, &PingBackoff{DelayMs: defaultPingDelayMs, Multiplier: 1}, request.Backoff)
+	assert.Nil(t, request.Validate())
+	assert.NotNil(t, (&PingRequest{}).Validate())
+	request.Datastores = map[string]*Readiness{"db2": {SchemaVersion: &SchemaVersion{Table: "schema_history"}}}
+	assert.NotNil(t, request.Validate())
+}
@@ -5,12 +5,37 @@ import (
 	"github.com/viant/dsc"
 	"github.com/viant/toolbox"
 	"strings"
+	"sync"
 )
 
 type sequence struct {
 	seq map[string]int64
 }
 
+// sequenceBases represents sequence values captured by the first symbolic <ds:seq["table", offset]> expression evaluation
+type sequenceBases struct {
+	mux   sync.Mutex
+	bases map[string]int64
+}
+
+func (b *sequenceBases) get(table string, fetch func() (int64, error)) (int64, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if base, ok := b.bases[table]; ok {
+		return base, nil
+	}
+	base, err := fetch()
+	if err != nil {
+		return 0, err
+	}
+	b.bases[table] = base
+	return base, nil
+}
+
+func newSequenceBases() *sequenceBases {
+	return &sequenceBases{bases: make(map[string]int64)}
+}
+
 type sequenceValueProvider struct {
 	match string
 }
@@ -50,8 +75,28 @@ func (p *sequenceValueProvider) fetchSequence(context toolbox.Context, sequenceN
 	return result, nil
 }
 
+// symbolicValue returns sequence base increased by offset, base is shared by all datasets using the same context
+func (p *sequenceValueProvider) symbolicValue(context toolbox.Context, sequenceName string, offset int64) (interface{}, error) {
+	if !context.Contains((*sequenceBases)(nil)) {
+		context.Put((*sequenceBases)(nil), newSequenceBases())
+	}
+	bases := context.GetOptional((*sequenceBases)(nil)).(*sequenceBases)
+	base, err := bases.get(sequenceName, func() (int64, error) {
+		manager := *context.GetOptional((*dsc.Manager)(nil)).(*dsc.Manager)
+		datastoreDialect := *context.GetOptional((*dsc.DatastoreDialect)(nil)).(*dsc.DatastoreDialect)
+		return datastoreDialect.GetSequence(manager, sequenceName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return base + offset, nil
+}
+
 func (p *sequenceValueProvider) Get(context toolbox.Context, arguments ...interface{}) (interface{}, error) {
 	sequenceName := toolbox.AsString(arguments[0])
+	if len(arguments) > 1 {
+		return p.symbolicValue(context, sequenceName, int64(toolbox.AsInt(arguments[1])))
+	}
 
 	if !context.Contains((*sequence)(nil)) {
 		seq, err := p.fetchSequence(context, sequenceName)
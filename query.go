@@ -0,0 +1,109 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"unicode"
+)
+
+// bindNamedParameters rewrites :name placeholders outside quoted text into positional ? placeholders, it returns parameters in placeholder order
+func bindNamedParameters(SQL string, params map[string]interface{}) (string, []interface{}, error) {
+	var result = make([]rune, 0, len(SQL))
+	var values = make([]interface{}, 0)
+	runes := []rune(SQL)
+	var quote rune
+	isNameRune := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	for i := 0; i < len(runes); i++ {
+		current := runes[i]
+		switch {
+		case quote != 0:
+			if current == quote {
+				quote = 0
+			}
+		case current == '\'' || current == '"' || current == '`':
+			quote = current
+		case current == ':' && i+1 < len(runes) && (runes[i+1] == '_' || unicode.IsLetter(runes[i+1])) && (i == 0 || runes[i-1] != ':'):
+			end := i + 1
+			for end < len(runes) && isNameRune(runes[end]) {
+				end++
+			}
+			name := string(runes[i+1 : end])
+			value, ok := params[name]
+			if !ok {
+				return "", nil, fmt.Errorf("named parameter :%v was not defined", name)
+			}
+			values = append(values, value)
+			result = append(result, '?')
+			i = end - 1
+			continue
+		}
+		result = append(result, current)
+	}
+	return string(result), values, nil
+}
+
+// queryParameters returns query SQL with bind parameters
+func (r *QueryRequest) queryParameters(SQL string) (string, []interface{}, error) {
+	if len(r.NamedParams) > 0 {
+		return bindNamedParameters(SQL, r.NamedParams)
+	}
+	return SQL, r.Params, nil
+}
+
+// expectedDataset returns expected dataset resource dataset for query records
+func (r *QueryRequest) expectedDataset() (*Dataset, error) {
+	if err := r.ExpectDataset.Load(); err != nil {
+		return nil, err
+	}
+	for _, dataset := range r.ExpectDataset.Datasets {
+		if dataset.Table == r.Dataset {
+			return dataset, nil
+		}
+	}
+	if r.Dataset == "" && len(r.ExpectDataset.Datasets) == 1 {
+		return r.ExpectDataset.Datasets[0], nil
+	}
+	return nil, fmt.Errorf("expected dataset %v was not found: %v/%v", r.Dataset, r.ExpectDataset.URL, r.ExpectDataset.Prefix+"*"+r.ExpectDataset.Postfix)
+}
+
+// assertRecords verifies actual records with expected dataset records, full table policy also verifies record count
+func (s *service) assertRecords(manager dsc.Manager, dataset *Dataset, actual Records, checkPolicy int) (*assertly.Validation, error) {
+	ctx := s.newContext(manager)
+	expandDataIfNeeded(ctx, dataset.Records)
+	expected, err := dataset.Records.Expand(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	validation, err := assertly.Assert(expected, actual, assertly.NewDataPath(dataset.Table))
+	if err != nil {
+		return nil, err
+	}
+	if checkPolicy == FullTableDatasetCheckPolicy {
+		expected = removeDirectiveRecord(expected)
+		if len(actual) != len(expected) {
+			validation.AddFailure(assertly.NewFailure("", "count", assertly.EqualViolation, len(expected), len(actual)))
+		}
+	}
+	return validation, nil
+}
+
+// expectQuery verifies query records with expected records, check policy only applies to expected dataset
+func (s *service) expectQuery(request *QueryRequest, manager dsc.Manager, response *QueryResponse) (err error) {
+	if request.ExpectDataset == nil {
+		response.Validation, err = assertly.Assert(request.Expect, response.Records, assertly.NewDataPath("sql"))
+		return err
+	}
+	dataset, err := request.expectedDataset()
+	if err != nil {
+		return err
+	}
+	validation, err := s.assertRecords(manager, dataset, response.Records, request.CheckPolicy)
+	if err != nil {
+		return err
+	}
+	response.Validation = validation
+	return nil
+}
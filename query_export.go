@@ -0,0 +1,227 @@
+package dsunit
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/dsc"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Query export formats
+const (
+	QueryFormatCSV    = "csv"
+	QueryFormatTSV    = "tsv"
+	QueryFormatNDJSON = "ndjson"
+	QueryFormatJSON   = "json"
+)
+
+// exportTimeLayout is used for time values in all export formats, it matches JSON encoding of time.Time
+const exportTimeLayout = time.RFC3339Nano
+
+// ExportFormat returns export format, derived from DestURL extension if not specified, NDJSON by default
+func (r *QueryRequest) ExportFormat() string {
+	if r.Format != "" {
+		return strings.ToLower(r.Format)
+	}
+	switch ext := strings.ToLower(strings.TrimPrefix(path.Ext(r.DestURL), ".")); ext {
+	case QueryFormatCSV, QueryFormatTSV, QueryFormatJSON:
+		return ext
+	}
+	return QueryFormatNDJSON
+}
+
+// recordExporter writes records in export format
+type recordExporter struct {
+	format        string
+	writer        io.WriteCloser
+	buffer        *bufio.Writer
+	csv           *csv.Writer
+	columns       []string
+	headerWritten bool
+	count         int
+}
+
+// writeHeader writes CSV and TSV header once
+func (e *recordExporter) writeHeader() error {
+	if e.csv == nil || e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.csv.Write(e.columns)
+}
+
+// write writes record, CSV and TSV header is written with the first record, time values use exportTimeLayout
+func (e *recordExporter) write(record map[string]interface{}) error {
+	for column, value := range record {
+		switch actual := value.(type) {
+		case []byte:
+			record[column] = string(actual)
+		case time.Time:
+			record[column] = actual.Format(exportTimeLayout)
+		case *time.Time:
+			if actual != nil {
+				record[column] = actual.Format(exportTimeLayout)
+			}
+		}
+	}
+	defer func() { e.count++ }()
+	switch e.format {
+	case QueryFormatCSV, QueryFormatTSV:
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+		var row = make([]string, 0, len(e.columns))
+		for _, column := range e.columns {
+			row = append(row, textValue(record[column]))
+		}
+		return e.csv.Write(row)
+	}
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if e.format == QueryFormatJSON {
+		separator := ",\n"
+		if e.count == 0 {
+			separator = "[\n"
+		}
+		if _, err = e.buffer.WriteString(separator); err != nil {
+			return err
+		}
+	}
+	if _, err = e.buffer.Write(encoded); err == nil && e.format == QueryFormatNDJSON {
+		err = e.buffer.WriteByte('\n')
+	}
+	return err
+}
+
+// Close writes closing bracket if needed, flushes and closes underlying writer
+func (e *recordExporter) Close() error {
+	var err error
+	switch e.format {
+	case QueryFormatCSV, QueryFormatTSV:
+		e.csv.Flush()
+		err = e.csv.Error()
+	case QueryFormatJSON:
+		closing := "\n]\n"
+		if e.count == 0 {
+			closing = "[]\n"
+		}
+		_, err = e.buffer.WriteString(closing)
+	}
+	if err == nil {
+		err = e.buffer.Flush()
+	}
+	if closeErr := e.writer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// newRecordExporter creates record exporter for supplied URL and format
+func newRecordExporter(URL, format string) (*recordExporter, error) {
+	switch format {
+	case QueryFormatCSV, QueryFormatTSV, QueryFormatNDJSON, QueryFormatJSON:
+	default:
+		return nil, fmt.Errorf("unsupported export format: %v, supported: %v, %v, %v, %v", format, QueryFormatCSV, QueryFormatTSV, QueryFormatNDJSON, QueryFormatJSON)
+	}
+	writer, err := afs.New().NewWriter(context.Background(), URL, file.DefaultFileOsMode)
+	if err != nil {
+		return nil, err
+	}
+	result := &recordExporter{format: format, writer: writer, buffer: bufio.NewWriter(writer)}
+	if format == QueryFormatCSV || format == QueryFormatTSV {
+		result.csv = csv.NewWriter(result.buffer)
+		if format == QueryFormatTSV {
+			result.csv.Comma = '\t'
+		}
+	}
+	return result, nil
+}
+
+// exportQuery streams query records to request DestURL without collecting them in response
+func (s *service) exportQuery(manager dsc.Manager, request *QueryRequest, SQL string, params []interface{}, response *QueryResponse) error {
+	response.DestURL = url.Normalize(request.DestURL, file.Scheme)
+	exporter, err := newRecordExporter(response.DestURL, request.ExportFormat())
+	if err != nil {
+		return err
+	}
+	err = manager.ReadAllWithHandler(SQL, params, func(scanner dsc.Scanner) (bool, error) {
+		if exporter.columns == nil {
+			columns, err := scanner.Columns()
+			if err != nil {
+				return false, err
+			}
+			exporter.columns = columns
+		}
+		record := make(map[string]interface{})
+		if err := scanner.Scan(record); err != nil {
+			return false, err
+		}
+		if err := exporter.write(record); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+	response.Count = exporter.count
+	if err == nil && exporter.count == 0 && exporter.csv != nil {
+		if exporter.columns, err = queryColumns(manager, SQL); err == nil {
+			err = exporter.writeHeader()
+		}
+	}
+	if closeErr := exporter.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// queryColumns returns query result columns derived from SQL, star is expanded with queried table columns, it is used when query returns no rows
+func queryColumns(manager dsc.Manager, SQL string) ([]string, error) {
+	statement, err := dsc.NewQueryParser().ParseQuery(SQL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read query columns: %v", err)
+	}
+	if len(statement.Columns) == 0 {
+		return tableColumns(manager, statement.Table)
+	}
+	var result = make([]string, 0, len(statement.Columns))
+	for _, column := range statement.Columns {
+		switch {
+		case column.Name == "*":
+			columns, err := tableColumns(manager, statement.Table)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, columns...)
+		case column.Alias != "":
+			result = append(result, column.Alias)
+		default:
+			result = append(result, column.Name)
+		}
+	}
+	return result, nil
+}
+
+// tableColumns returns table column names
+func tableColumns(manager dsc.Manager, table string) ([]string, error) {
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, _ := dialect.GetCurrentDatastore(manager)
+	columns, err := dialect.GetColumns(manager, datastore, table)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %v columns: %v", table, err)
+	}
+	var result = make([]string, 0, len(columns))
+	for _, column := range columns {
+		result = append(result, column.Name())
+	}
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package dsunit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRecordExporter_Write(t *testing.T) {
+	created := time.Date(2019, 3, 1, 10, 15, 0, 0, time.UTC)
+	records := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"id": 1, "name": []byte("Bob"), "created": created},
+			{"id": 2, "name": "Ann, Jr", "created": nil},
+		}
+	}
+	var useCases = []struct {
+		format string
+		expect string
+	}{
+		{QueryFormatCSV, "id,name,created\n1,Bob,2019-03-01T10:15:00Z\n2,\"Ann, Jr\",\n"},
+		{QueryFormatTSV, "id\tname\tcreated\n1\tBob\t2019-03-01T10:15:00Z\n2\tAnn, Jr\t\n"},
+		{QueryFormatNDJSON, "{\"created\":\"2019-03-01T10:15:00Z\",\"id\":1,\"name\":\"Bob\"}\n{\"created\":null,\"id\":2,\"name\":\"Ann, Jr\"}\n"},
+		{QueryFormatJSON, "[\n{\"created\":\"2019-03-01T10:15:00Z\",\"id\":1,\"name\":\"Bob\"},\n{\"created\":null,\"id\":2,\"name\":\"Ann, Jr\"}\n]\n"},
+	}
+	for _, useCase := range useCases {
+		buffer := &bufferCloser{Buffer: new(bytes.Buffer)}
+		exporter := &recordExporter{format: useCase.format, writer: buffer, buffer: bufio.NewWriter(buffer), columns: []string{"id", "name", "created"}}
+		if useCase.format == QueryFormatCSV || useCase.format == QueryFormatTSV {
+			exporter.csv = csv.NewWriter(exporter.buffer)
+			if useCase.format == QueryFormatTSV {
+				exporter.csv.Comma = '\t'
+			}
+		}
+		for _, record := range records() {
+			assert.Nil(t, exporter.write(record), useCase.format)
+		}
+		assert.Nil(t, exporter.Close(), useCase.format)
+		assert.Equal(t, useCase.expect, buffer.String(), useCase.format)
+		assert.Equal(t, 2, exporter.count, useCase.format)
+	}
+}
+
+func TestRecordExporter_WriteHeader(t *testing.T) {
+	buffer := &bufferCloser{Buffer: new(bytes.Buffer)}
+	exporter := &recordExporter{format: QueryFormatCSV, writer: buffer, buffer: bufio.NewWriter(buffer), columns: []string{"id", "name"}}
+	exporter.csv = csv.NewWriter(exporter.buffer)
+	assert.Nil(t, exporter.writeHeader())
+	assert.Nil(t, exporter.write(map[string]interface{}{"id": 1, "name": "Bob"}))
+	assert.Nil(t, exporter.Close())
+	assert.Equal(t, "id,name\n1,Bob\n", buffer.String())
+}
+
+func TestQueryRequest_ExportFormat(t *testing.T) {
+	assert.Equal(t, QueryFormatCSV, (&QueryRequest{DestURL: "/tmp/users.CSV"}).ExportFormat())
+	assert.Equal(t, QueryFormatJSON, (&QueryRequest{DestURL: "/tmp/users.json"}).ExportFormat())
+	assert.Equal(t, QueryFormatNDJSON, (&QueryRequest{DestURL: "/tmp/users.out"}).ExportFormat())
+	assert.Equal(t, QueryFormatTSV, (&QueryRequest{DestURL: "/tmp/users.out", Format: "TSV"}).ExportFormat())
+}
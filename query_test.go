@@ -0,0 +1,16 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBindNamedParameters(t *testing.T) {
+	SQL, params, err := bindNamedParameters("SELECT a::INT, ':x' FROM t WHERE a = :a AND b = :b_1 OR c = :a", map[string]interface{}{"a": 1, "b_1": 2})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "SELECT a::INT, ':x' FROM t WHERE a = ? AND b = ? OR c = ?", SQL)
+		assert.EqualValues(t, []interface{}{1, 2, 1}, params)
+	}
+	_, _, err = bindNamedParameters("SELECT * FROM t WHERE id = :id", nil)
+	assert.NotNil(t, err)
+}
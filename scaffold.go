@@ -0,0 +1,168 @@
+package dsunit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/viant/afs"
+	"github.com/viant/afs/url"
+	dsurl "github.com/viant/dsunit/url"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// driverPackages maps datastore driver name to go driver package imported by the generated test
+var driverPackages = map[string]string{
+	"mysql":     "github.com/go-sql-driver/mysql",
+	"postgres":  "github.com/lib/pq",
+	"sqlite3":   "github.com/mattn/go-sqlite3",
+	"bigquery":  "github.com/viant/bgc",
+	"aerospike": "github.com/viant/asc",
+}
+
+var scaffoldTestTemplate = template.Must(template.New("test").Parse(`package {{.Package}}
+
+import (
+	"testing"
+
+	"github.com/viant/dsunit"
+{{- if .Driver}}
+
+	_ "{{.Driver}}"
+{{- end}}
+)
+
+func {{.Test}}(t *testing.T) {
+	if !dsunit.InitFromURL(t, "{{.InitURL}}") {
+		return
+	}
+	if !dsunit.PrepareDatastore(t, "{{.Datastore}}") {
+		return
+	}
+
+	//add tested logic here
+
+	dsunit.ExpectDatasets(t, "{{.Datastore}}", dsunit.{{.CheckPolicy}})
+}
+`))
+
+// packageName returns go package name derived from the last URL path segment
+func packageName(URL string) string {
+	var result = make([]rune, 0)
+	for _, r := range strings.ToLower(path.Base(url.Path(URL))) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			result = append(result, r)
+		}
+	}
+	if len(result) == 0 || unicode.IsDigit(result[0]) {
+		return "test"
+	}
+	return string(result)
+}
+
+// scaffoldPackage returns package clause of go files in dest URL, non test files take precedence, package name derived from dest URL is used if there are no go files
+func scaffoldPackage(URL string) string {
+	ctx := context.Background()
+	fs := afs.New()
+	objects, _ := fs.List(ctx, URL)
+	var testPackage string
+	for _, object := range objects {
+		if object.IsDir() || !strings.HasSuffix(object.Name(), ".go") {
+			continue
+		}
+		data, err := fs.DownloadWithURL(ctx, object.URL())
+		if err != nil {
+			continue
+		}
+		parsed, err := parser.ParseFile(token.NewFileSet(), object.Name(), data, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		if !strings.HasSuffix(object.Name(), "_test.go") {
+			return parsed.Name.Name
+		}
+		if testPackage == "" {
+			testPackage = parsed.Name.Name
+		}
+	}
+	if testPackage != "" {
+		return testPackage
+	}
+	return packageName(URL)
+}
+
+// scaffoldTest returns go test skeleton source
+func scaffoldTest(request *ScaffoldRequest, driverName string) ([]byte, error) {
+	checkPolicy := "FullTableDatasetCheckPolicy"
+	if request.CheckPolicy == SnapshotDatasetCheckPolicy {
+		checkPolicy = "SnapshotDatasetCheckPolicy"
+	}
+	var source = new(bytes.Buffer)
+	err := scaffoldTestTemplate.Execute(source, map[string]string{
+		"Package":     request.Package,
+		"Driver":      driverPackages[driverName],
+		"Test":        request.Test,
+		"InitURL":     request.InitURL,
+		"Datastore":   request.Datastore,
+		"CheckPolicy": checkPolicy,
+	})
+	return source.Bytes(), err
+}
+
+// scaffoldFreezeRequest returns freeze request for supplied table dataset and operation (prepare or expect)
+func scaffoldFreezeRequest(request *ScaffoldRequest, table, SQL, operation string) *FreezeRequest {
+	var result = *request.Freeze
+	result.Datastore = request.Datastore
+	result.SQL = SQL
+	result.Table = table
+	result.KeyDirective = true
+	prefix := datasetFilePrefix(request.TestFile, request.Test, operation)
+	result.DestURL = url.Join(request.DestURL, prefix+table+".json")
+	result.Reset = operation == "prepare"
+	result.ExpectMode = operation == "expect"
+	return &result
+}
+
+func (s *service) scaffold(request *ScaffoldRequest, response *ScaffoldResponse) error {
+	var SQLs = request.DatasetSQLs()
+	var tables = make([]string, 0, len(SQLs))
+	for table := range SQLs {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, operation := range []string{"prepare", "expect"} {
+		for _, table := range tables {
+			freezeResponse := s.Freeze(scaffoldFreezeRequest(request, table, SQLs[table], operation))
+			if freezeResponse.Status != StatusOk {
+				return fmt.Errorf("failed to freeze %v %v dataset: %v", table, operation, freezeResponse.Message)
+			}
+			response.Datasets = append(response.Datasets, freezeResponse.DestURL)
+		}
+	}
+
+	testResource := dsurl.NewResource(url.Join(request.DestURL, request.TestFile+".go"))
+	if !request.Overwrite {
+		exists, err := afs.New().Exists(context.Background(), testResource.URL)
+		if err != nil || exists {
+			return err
+		}
+	}
+	if request.Package == "" {
+		request.Package = scaffoldPackage(request.DestURL)
+	}
+	manager := s.registry.Get(request.Datastore)
+	source, err := scaffoldTest(request, manager.Config().DriverName)
+	if err != nil {
+		return err
+	}
+	uploadContent(testResource, response.BaseResponse, source)
+	if response.Status == StatusOk {
+		response.TestURL = testResource.URL
+	}
+	return nil
+}
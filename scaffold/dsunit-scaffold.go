@@ -0,0 +1,92 @@
+// Package main - dsunit test scaffold generator
+//
+// Usage:
+//
+//	dsunit-scaffold -register config/db.json -init test/init.json -datastore db1 -tables users,orders -test TestUserService_Create -dest service/user
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/viant/dsunit"
+	//sql drivers known to scaffold, the generated test imports only the registered datastore driver
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	var requestURL, registerURL, initURL, datastore, tables, test, destURL string
+	var snapshot bool
+	flag.StringVar(&requestURL, "request", "", "scaffold JSON/YAML request URL, other scaffold flags are ignored")
+	flag.StringVar(&registerURL, "register", "", "source database register request URL, init request datastore config is used if empty")
+	flag.StringVar(&initURL, "init", "test/init.json", "dsunit init request URL used by generated test")
+	flag.StringVar(&datastore, "datastore", "", "registered datastore i.e. db1")
+	flag.StringVar(&tables, "tables", "", "comma separated tables")
+	flag.StringVar(&test, "test", "", "go test name i.e. TestUserService_Create")
+	flag.StringVar(&destURL, "dest", ".", "test package location")
+	flag.BoolVar(&snapshot, "snapshot", false, "use SnapshotDatasetCheckPolicy in generated test")
+	flag.Parse()
+
+	var request = &dsunit.ScaffoldRequest{
+		Datastore: datastore,
+		Test:      test,
+		DestURL:   destURL,
+		InitURL:   initURL,
+	}
+	if tables != "" {
+		request.Tables = strings.Split(tables, ",")
+	}
+	if snapshot {
+		request.CheckPolicy = dsunit.SnapshotDatasetCheckPolicy
+	}
+	if requestURL != "" {
+		var err error
+		if request, err = dsunit.NewScaffoldRequestFromURL(requestURL); err != nil {
+			log.Fatal(err)
+		}
+		if request.InitURL == "" {
+			request.InitURL = initURL
+		}
+	}
+
+	registerRequest, err := getRegisterRequest(registerURL, request.InitURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	service := dsunit.New()
+	if response := service.Register(registerRequest); response.Status != dsunit.StatusOk {
+		log.Fatal(response.Message)
+	}
+	response := service.Scaffold(request)
+	if response.Status != dsunit.StatusOk {
+		log.Fatal(response.Message)
+	}
+	for _, URL := range response.Datasets {
+		fmt.Println(URL)
+	}
+	if response.TestURL != "" {
+		fmt.Println(response.TestURL)
+	}
+}
+
+// getRegisterRequest returns register request, only datastore config is taken from init request, so that live database is never recreated
+func getRegisterRequest(registerURL, initURL string) (*dsunit.RegisterRequest, error) {
+	if registerURL != "" {
+		return dsunit.NewRegisterRequestFromURL(registerURL)
+	}
+	initRequest, err := dsunit.NewInitRequestFromURL(initURL)
+	if err != nil {
+		return nil, err
+	}
+	if err = initRequest.Init(); err != nil {
+		return nil, err
+	}
+	if initRequest.RegisterRequest == nil {
+		return nil, fmt.Errorf("register request was empty: %v", initURL)
+	}
+	return initRequest.RegisterRequest, nil
+}
@@ -0,0 +1,28 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestScaffoldPackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dsunit_scaffold")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	assert.Equal(t, packageName(dir), scaffoldPackage(dir))
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "user_test.go"), []byte("package user_test\n"), 0644))
+	assert.Equal(t, "user_test", scaffoldPackage(dir))
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "user.go"), []byte("// Package user\npackage user\n\nfunc Get() {}\n"), 0644))
+	assert.Equal(t, "user", scaffoldPackage(dir))
+
+	request := &ScaffoldRequest{Test: "TestUser_Get", DestURL: "."}
+	assert.Nil(t, request.Init())
+	wd, _ := os.Getwd()
+	assert.Equal(t, packageName(wd), packageName(request.DestURL))
+	assert.NotEqual(t, "test", packageName(request.DestURL))
+}
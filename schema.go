@@ -0,0 +1,410 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TableIndex represents secondary index or unique constraint
+type TableIndex struct {
+	Name    string
+	Table   string
+	Columns []string
+	Unique  bool
+}
+
+// ForeignKey represents foreign key constraint
+type ForeignKey struct {
+	Name       string
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string
+}
+
+// View represents a view
+type View struct {
+	Name string
+	SQL  string
+}
+
+// Sequence represents standalone sequence
+type Sequence struct {
+	Name      string
+	Start     int64
+	Increment int64
+}
+
+// TableSchema represents table descriptor with defaults, indexes and foreign keys
+type TableSchema struct {
+	*dsc.TableDescriptor
+	Defaults    map[string]string
+	Indexes     []*TableIndex
+	ForeignKeys []*ForeignKey
+}
+
+// schemaQueries represents driver specific catalog queries, %[1]v is replaced with datastore, %[2]v with table
+type schemaQueries struct {
+	indexes     string
+	foreignKeys string
+	defaults    string
+	views       string
+	sequences   string
+}
+
+var mysqlSchemaQueries = &schemaQueries{
+	indexes: `SELECT INDEX_NAME AS index_name, COLUMN_NAME AS column_name, CASE WHEN NON_UNIQUE = 0 THEN 1 ELSE 0 END AS is_unique
+FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = '%[1]v' AND TABLE_NAME = '%[2]v' AND INDEX_NAME <> 'PRIMARY'
+ORDER BY INDEX_NAME, SEQ_IN_INDEX`,
+	foreignKeys: `SELECT k.CONSTRAINT_NAME AS constraint_name, k.COLUMN_NAME AS column_name, k.REFERENCED_TABLE_NAME AS ref_table, k.REFERENCED_COLUMN_NAME AS ref_column, r.DELETE_RULE AS delete_rule
+FROM information_schema.KEY_COLUMN_USAGE k
+JOIN information_schema.REFERENTIAL_CONSTRAINTS r ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+WHERE k.TABLE_SCHEMA = '%[1]v' AND k.TABLE_NAME = '%[2]v' AND k.REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION`,
+	defaults: `SELECT COLUMN_NAME AS column_name, COLUMN_DEFAULT AS column_default
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = '%[1]v' AND TABLE_NAME = '%[2]v' AND COLUMN_DEFAULT IS NOT NULL`,
+	views: `SELECT TABLE_NAME AS name, VIEW_DEFINITION AS definition
+FROM information_schema.VIEWS
+WHERE TABLE_SCHEMA = '%[1]v'`,
+}
+
+var postgresSchemaQueries = &schemaQueries{
+	indexes: `SELECT i.relname AS index_name, a.attname AS column_name, CASE WHEN ix.indisunique THEN 1 ELSE 0 END AS is_unique
+FROM pg_index ix
+JOIN pg_class t ON t.oid = ix.indrelid
+JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, position) ON TRUE
+JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+WHERE n.nspname = current_schema() AND t.relname = '%[2]v' AND NOT ix.indisprimary
+ORDER BY i.relname, k.position`,
+	foreignKeys: `SELECT c.conname AS constraint_name, a.attname AS column_name, rt.relname AS ref_table, ra.attname AS ref_column,
+CASE c.confdeltype WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END AS delete_rule
+FROM pg_constraint c
+JOIN pg_class t ON t.oid = c.conrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_class rt ON rt.oid = c.confrelid
+JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, refattnum, position) ON TRUE
+JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = k.refattnum
+WHERE c.contype = 'f' AND n.nspname = current_schema() AND t.relname = '%[2]v'
+ORDER BY c.conname, k.position`,
+	defaults: `SELECT column_name, column_default
+FROM information_schema.columns
+WHERE table_schema = current_schema() AND table_name = '%[2]v' AND column_default IS NOT NULL`,
+	views: `SELECT table_name AS name, view_definition AS definition
+FROM information_schema.views
+WHERE table_schema = current_schema()`,
+	sequences: `SELECT s.relname AS name, q.seqstart AS start_value, q.seqincrement AS increment
+FROM pg_class s
+JOIN pg_namespace n ON n.oid = s.relnamespace
+JOIN pg_sequence q ON q.seqrelid = s.oid
+WHERE s.relkind = 'S' AND n.nspname = current_schema()
+AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = s.oid AND d.deptype IN ('a', 'i'))
+ORDER BY s.relname`,
+}
+
+var sqliteSchemaQueries = &schemaQueries{
+	indexes: `SELECT l.name AS index_name, i.name AS column_name, l."unique" AS is_unique
+FROM pragma_index_list('%[2]v') l
+JOIN pragma_index_info(l.name) i
+WHERE l.origin <> 'pk'
+ORDER BY l.name, i.seqno`,
+	foreignKeys: `SELECT 'fk_%[2]v_' || id AS constraint_name, "from" AS column_name, "table" AS ref_table, "to" AS ref_column, on_delete AS delete_rule
+FROM pragma_foreign_key_list('%[2]v')
+ORDER BY id, seq`,
+	defaults: `SELECT name AS column_name, dflt_value AS column_default
+FROM pragma_table_info('%[2]v')
+WHERE dflt_value IS NOT NULL`,
+	views: `SELECT name, sql AS definition
+FROM sqlite_master
+WHERE type = 'view'`,
+}
+
+var schemaQueriesByDriver = map[string]*schemaQueries{
+	"mysql":    mysqlSchemaQueries,
+	"postgres": postgresSchemaQueries,
+	"pgx":      postgresSchemaQueries,
+	"sqlite3":  sqliteSchemaQueries,
+}
+
+var createViewExpr = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:TEMP\w*\s+)?VIEW\s+(?:IF\s+NOT\s+EXISTS\s+)?\S+\s+AS\s+(.+)$`)
+
+// readSchemaRecords runs catalog query, empty query returns no records
+func readSchemaRecords(manager dsc.Manager, query, datastore, table string) ([]map[string]interface{}, error) {
+	var records = make([]map[string]interface{}, 0)
+	if query == "" {
+		return records, nil
+	}
+	SQL := query
+	if strings.Contains(query, "%[") {
+		SQL = fmt.Sprintf(query, strings.Replace(datastore, "'", "''", -1), strings.Replace(table, "'", "''", -1))
+	}
+	if err := manager.ReadAll(&records, SQL, nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to read %v schema: %v", table, err)
+	}
+	return records, nil
+}
+
+func getSchemaQueries(manager dsc.Manager) *schemaQueries {
+	if queries, ok := schemaQueriesByDriver[manager.Config().DriverName]; ok {
+		return queries
+	}
+	return &schemaQueries{}
+}
+
+// tableSchema returns table descriptor with defaults, indexes and foreign keys
+func (s *service) tableSchema(manager dsc.Manager, table, mappingURL, target string) (*TableSchema, error) {
+	descriptor, err := s.TableInfo(manager, table, mappingURL, target)
+	if err != nil {
+		return nil, err
+	}
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, _ := dialect.GetCurrentDatastore(manager)
+	descriptor.PkColumns = nonEmpty(descriptor.PkColumns)
+	descriptor.Autoincrement = len(descriptor.PkColumns) == 1 && dialect.IsAutoincrement(manager, datastore, table)
+	result := &TableSchema{
+		TableDescriptor: descriptor,
+		Defaults:        make(map[string]string),
+		Indexes:         make([]*TableIndex, 0),
+		ForeignKeys:     make([]*ForeignKey, 0),
+	}
+	queries := getSchemaQueries(manager)
+	records, err := readSchemaRecords(manager, queries.defaults, datastore, table)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		value := toolbox.AsString(record["column_default"])
+		if isSequenceDefault(value) {
+			descriptor.Autoincrement = len(descriptor.PkColumns) == 1
+			continue
+		}
+		result.Defaults[toolbox.AsString(record["column_name"])] = value
+	}
+	if records, err = readSchemaRecords(manager, queries.indexes, datastore, table); err != nil {
+		return nil, err
+	}
+	var indexes = make(map[string]*TableIndex)
+	for _, record := range records {
+		name := toolbox.AsString(record["index_name"])
+		index, ok := indexes[name]
+		if !ok {
+			index = &TableIndex{Name: name, Table: table, Unique: toolbox.AsInt(record["is_unique"]) == 1}
+			indexes[name] = index
+			result.Indexes = append(result.Indexes, index)
+		}
+		index.Columns = append(index.Columns, toolbox.AsString(record["column_name"]))
+	}
+	for _, index := range result.Indexes {
+		if strings.HasPrefix(index.Name, "sqlite_autoindex") {
+			index.Name = fmt.Sprintf("%v_%v_idx", table, strings.Join(index.Columns, "_"))
+		}
+	}
+	if records, err = readSchemaRecords(manager, queries.foreignKeys, datastore, table); err != nil {
+		return nil, err
+	}
+	var foreignKeys = make(map[string]*ForeignKey)
+	for _, record := range records {
+		name := toolbox.AsString(record["constraint_name"])
+		foreignKey, ok := foreignKeys[name]
+		if !ok {
+			foreignKey = &ForeignKey{Name: name, Table: table, RefTable: toolbox.AsString(record["ref_table"])}
+			if rule := strings.ToUpper(toolbox.AsString(record["delete_rule"])); rule != "" && rule != "NO ACTION" && rule != "RESTRICT" {
+				foreignKey.OnDelete = rule
+			}
+			foreignKeys[name] = foreignKey
+			result.ForeignKeys = append(result.ForeignKeys, foreignKey)
+		}
+		foreignKey.Columns = append(foreignKey.Columns, toolbox.AsString(record["column_name"]))
+		if refColumn := record["ref_column"]; refColumn != nil {
+			foreignKey.RefColumns = append(foreignKey.RefColumns, toolbox.AsString(refColumn))
+		}
+	}
+	return result, nil
+}
+
+// readViews returns datastore views ordered by dependency
+func (s *service) readViews(manager dsc.Manager) ([]*View, error) {
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, _ := dialect.GetCurrentDatastore(manager)
+	records, err := readSchemaRecords(manager, getSchemaQueries(manager).views, datastore, "")
+	if err != nil {
+		return nil, err
+	}
+	var views = make([]*View, 0)
+	for _, record := range records {
+		SQL := strings.TrimSpace(toolbox.AsString(record["definition"]))
+		if matched := createViewExpr.FindStringSubmatch(SQL); len(matched) > 1 {
+			SQL = matched[1]
+		}
+		views = append(views, &View{Name: toolbox.AsString(record["name"]), SQL: strings.TrimRight(SQL, "; \n")})
+	}
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].Name < views[j].Name
+	})
+	return orderViews(views), nil
+}
+
+// readSequences returns standalone sequences
+func (s *service) readSequences(manager dsc.Manager) ([]*Sequence, error) {
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, _ := dialect.GetCurrentDatastore(manager)
+	records, err := readSchemaRecords(manager, getSchemaQueries(manager).sequences, datastore, "")
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*Sequence, 0)
+	for _, record := range records {
+		result = append(result, &Sequence{
+			Name:      toolbox.AsString(record["name"]),
+			Start:     int64(toolbox.AsInt(record["start_value"])),
+			Increment: int64(toolbox.AsInt(record["increment"])),
+		})
+	}
+	return result, nil
+}
+
+var viewReferenceExpr = `(?i)(^|[^\w.])(\w+\.)?%v([^\w]|$)`
+
+// orderByForeignKeys returns tables ordered so that referenced tables go before referencing ones
+func orderByForeignKeys(tables []*TableSchema) []*TableSchema {
+	var byName = make(map[string]*TableSchema)
+	for _, table := range tables {
+		byName[strings.ToLower(table.Table)] = table
+	}
+	var result = make([]*TableSchema, 0, len(tables))
+	var visited = make(map[string]bool)
+	var visit func(table *TableSchema)
+	visit = func(table *TableSchema) {
+		key := strings.ToLower(table.Table)
+		if visited[key] {
+			return
+		}
+		visited[key] = true
+		for _, foreignKey := range table.ForeignKeys {
+			if referenced, ok := byName[strings.ToLower(foreignKey.RefTable)]; ok {
+				visit(referenced)
+			}
+		}
+		result = append(result, table)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+	return result
+}
+
+// orderViews orders views so that views referenced by other views come first
+func orderViews(views []*View) []*View {
+	var result = make([]*View, 0, len(views))
+	var placed = make(map[string]bool)
+	for len(result) < len(views) {
+		progress := false
+		for _, view := range views {
+			if placed[view.Name] {
+				continue
+			}
+			ready := true
+			for _, candidate := range views {
+				if candidate == view || placed[candidate.Name] {
+					continue
+				}
+				if regexp.MustCompile(fmt.Sprintf(viewReferenceExpr, regexp.QuoteMeta(candidate.Name))).MatchString(view.SQL) {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				placed[view.Name] = true
+				result = append(result, view)
+				progress = true
+			}
+		}
+		if !progress { //circular reference, keep remaining views in original order
+			for _, view := range views {
+				if !placed[view.Name] {
+					placed[view.Name] = true
+					result = append(result, view)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// dumpedViews returns views listed in tables or referencing listed tables, directly or through other returned views
+func dumpedViews(views []*View, tables []string) []*View {
+	var names = make(map[string]bool)
+	for _, table := range tables {
+		names[strings.ToLower(table)] = true
+	}
+	var result = make([]*View, 0)
+	var dumped = make(map[*View]bool)
+	for progress := true; progress; {
+		progress = false
+		for _, view := range views {
+			if dumped[view] {
+				continue
+			}
+			if !names[strings.ToLower(view.Name)] && !referencesAny(view.SQL, names) {
+				continue
+			}
+			dumped[view] = true
+			names[strings.ToLower(view.Name)] = true
+			progress = true
+		}
+	}
+	for _, view := range views {
+		if dumped[view] {
+			result = append(result, view)
+		}
+	}
+	return result
+}
+
+// referencedSequences returns sequences referenced by any of supplied DDLs or column defaults
+func referencedSequences(sequences []*Sequence, texts []string) []*Sequence {
+	var result = make([]*Sequence, 0)
+	for _, sequence := range sequences {
+		expr := regexp.MustCompile(fmt.Sprintf(viewReferenceExpr, regexp.QuoteMeta(sequence.Name)))
+		for _, text := range texts {
+			if expr.MatchString(text) {
+				result = append(result, sequence)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// referencesAny returns true if SQL references any of supplied lower case names
+func referencesAny(SQL string, names map[string]bool) bool {
+	for name := range names {
+		if regexp.MustCompile(fmt.Sprintf(viewReferenceExpr, regexp.QuoteMeta(name))).MatchString(SQL) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSequenceDefault(value string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(value)), "nextval(")
+}
+
+func nonEmpty(values []string) []string {
+	var result = make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
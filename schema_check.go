@@ -0,0 +1,368 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/assertly"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Schema difference kinds
+const (
+	SchemaDiffMissingTable  = "missingTable"
+	SchemaDiffExtraTable    = "extraTable"
+	SchemaDiffMissingColumn = "missingColumn"
+	SchemaDiffExtraColumn   = "extraColumn"
+	SchemaDiffColumnType    = "columnType"
+	SchemaDiffNullable      = "nullable"
+	SchemaDiffPrimaryKey    = "primaryKey"
+	SchemaDiffIndex         = "index"
+	SchemaDiffUnique        = "unique"
+	SchemaDiffDefault       = "default"
+	SchemaDiffColumnOrder   = "columnOrder"
+	SchemaDiffForeignKey    = "foreignKey"
+)
+
+// Schema difference severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+	SeverityIgnore  = "ignore"
+)
+
+var defaultSchemaSeverity = map[string]string{
+	SchemaDiffExtraColumn: SeverityWarning,
+	SchemaDiffIndex:       SeverityWarning,
+	SchemaDiffDefault:     SeverityWarning,
+	SchemaDiffColumnOrder: SeverityWarning,
+}
+
+// SchemaRules represents schema check rules
+type SchemaRules struct {
+	EquivalentTypes []string          `description:"equivalent types i.e. INT ~ INTEGER, VARCHAR(n) ~ STRING, lower case parameters are placeholders, type without parameters matches any parameters"`
+	IgnoreTables    []string          `description:"table name patterns to ignore i.e. tmp_*"`
+	IgnoreColumns   []string          `description:"column patterns to ignore, column or table.column i.e. updated, users.*_hash"`
+	Severity        map[string]string `description:"difference kind to severity (error, warning, info, ignore), error is used by default, except for extraColumn, index, default and columnOrder (warning)"`
+	equivalences    [][]*typePattern
+}
+
+// typePattern represents equivalence rule side, i.e. VARCHAR(n)
+type typePattern struct {
+	name   string
+	params []string
+}
+
+var typeExpr = regexp.MustCompile(`^([^(]+)(\((.*)\))?(.*)$`)
+var placeholderExpr = regexp.MustCompile(`^[a-z]\w*$`)
+
+// parseType returns upper case type name with parameters, trailing modifiers are appended to name i.e. TIMESTAMP WITH TIME ZONE
+func parseType(dbType string) (string, []string) {
+	matched := typeExpr.FindStringSubmatch(strings.TrimSpace(dbType))
+	if len(matched) == 0 {
+		return strings.ToUpper(strings.TrimSpace(dbType)), nil
+	}
+	name := strings.ToUpper(strings.Join(strings.Fields(matched[1]+" "+matched[4]), " "))
+	var params []string
+	if matched[2] != "" {
+		for _, param := range strings.Split(matched[3], ",") {
+			params = append(params, strings.TrimSpace(param))
+		}
+	}
+	return name, params
+}
+
+// Init parses equivalent types rules
+func (r *SchemaRules) Init() error {
+	r.equivalences = make([][]*typePattern, 0)
+	for _, rule := range r.EquivalentTypes {
+		var patterns = make([]*typePattern, 0)
+		for _, side := range strings.Split(rule, "~") {
+			if strings.TrimSpace(side) == "" {
+				return fmt.Errorf("invalid equivalent types rule: %v", rule)
+			}
+			name, params := parseType(side)
+			patterns = append(patterns, &typePattern{name: name, params: params})
+		}
+		if len(patterns) < 2 {
+			return fmt.Errorf("invalid equivalent types rule: %v, expected A ~ B", rule)
+		}
+		r.equivalences = append(r.equivalences, patterns)
+	}
+	return nil
+}
+
+// match returns captured placeholders if type matches pattern
+func (p *typePattern) match(name string, params []string) (map[string]string, bool) {
+	var captured = make(map[string]string)
+	if name != p.name {
+		return nil, false
+	}
+	if len(p.params) == 0 {
+		return captured, true
+	}
+	if len(params) != len(p.params) {
+		return nil, false
+	}
+	for i, param := range p.params {
+		if placeholderExpr.MatchString(param) {
+			captured[param] = params[i]
+			continue
+		}
+		if !strings.EqualFold(param, params[i]) {
+			return nil, false
+		}
+	}
+	return captured, true
+}
+
+// Equivalent returns true if types are the same or declared equivalent
+func (r *SchemaRules) Equivalent(type1, type2 string) bool {
+	name1, params1 := parseType(type1)
+	name2, params2 := parseType(type2)
+	if name1 == name2 && strings.Join(params1, ",") == strings.Join(params2, ",") {
+		return true
+	}
+	if r == nil {
+		return false
+	}
+	for _, patterns := range r.equivalences {
+		for _, pattern1 := range patterns {
+			captured1, ok := pattern1.match(name1, params1)
+			if !ok {
+				continue
+			}
+			for _, pattern2 := range patterns {
+				captured2, ok := pattern2.match(name2, params2)
+				if !ok {
+					continue
+				}
+				if sameCaptures(captured1, captured2) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func sameCaptures(captured1, captured2 map[string]string) bool {
+	for key, value := range captured1 {
+		if other, ok := captured2[key]; ok && !strings.EqualFold(value, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// IgnoreTable returns true if table matches ignore table pattern
+func (r *SchemaRules) IgnoreTable(table string) bool {
+	if r == nil {
+		return false
+	}
+	for _, pattern := range r.IgnoreTables {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(table)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoreColumn returns true if table column matches ignore column pattern
+func (r *SchemaRules) IgnoreColumn(table, column string) bool {
+	if r == nil {
+		return false
+	}
+	name := strings.ToLower(table + "." + column)
+	for _, pattern := range r.IgnoreColumns {
+		if !strings.Contains(pattern, ".") {
+			pattern = "*." + pattern
+		}
+		if matched, _ := path.Match(strings.ToLower(pattern), name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SeverityOf returns severity for difference kind
+func (r *SchemaRules) SeverityOf(kind string) string {
+	if r != nil {
+		if severity, ok := r.Severity[kind]; ok {
+			return severity
+		}
+	}
+	if severity, ok := defaultSchemaSeverity[kind]; ok {
+		return severity
+	}
+	return SeverityError
+}
+
+// SchemaDifference represents schema difference
+type SchemaDifference struct {
+	Kind     string
+	Severity string
+	Column   string `description:"column, index or constraint"`
+	Source   string
+	Dest     string
+	Message  string
+}
+
+// addDifference adds difference with rule severity, error differences are also reported as validation failures
+func (c *SchemaTableCheck) addDifference(rules *SchemaRules, difference *SchemaDifference) {
+	difference.Severity = rules.SeverityOf(difference.Kind)
+	switch difference.Severity {
+	case SeverityIgnore:
+		return
+	case SeverityError:
+		c.Errors++
+		dataPath := c.Table
+		if difference.Column != "" {
+			dataPath += "/" + difference.Column
+		}
+		c.Validation.AddFailure(assertly.NewFailure("", dataPath, difference.Message, difference.Source, difference.Dest))
+	case SeverityWarning:
+		c.Warnings++
+	}
+	c.Differences = append(c.Differences, difference)
+}
+
+// checkTableSchema compares source and dest table
+func checkTableSchema(request *CheckSchemaRequest, source, dest *TableSchema) *SchemaTableCheck {
+	var rules = request.Rules
+	var result = &SchemaTableCheck{Table: source.Table, Validation: assertly.NewValidation(), Differences: make([]*SchemaDifference, 0)}
+	var sourceColumns = make([]string, 0)
+	for _, column := range source.Columns {
+		if rules.IgnoreColumn(source.Table, column) {
+			continue
+		}
+		sourceColumns = append(sourceColumns, column)
+		destType, ok := dest.ColumnTypes[column]
+		if !ok {
+			result.addDifference(rules, &SchemaDifference{Kind: SchemaDiffMissingColumn, Column: column, Source: source.ColumnTypes[column], Message: "missing column in dest"})
+			continue
+		}
+		if rules.Equivalent(source.ColumnTypes[column], destType) {
+			result.PassedCount++
+		} else {
+			result.addDifference(rules, &SchemaDifference{Kind: SchemaDiffColumnType, Column: column, Source: source.ColumnTypes[column], Dest: destType, Message: "column type mismatch"})
+		}
+		if request.CheckNullables {
+			sourceNullable, hasSource := source.Nullables[column]
+			destNullable, hasDest := dest.Nullables[column]
+			if hasSource && hasDest && sourceNullable != destNullable {
+				result.addDifference(rules, &SchemaDifference{Kind: SchemaDiffNullable, Column: column, Source: fmt.Sprintf("%v", sourceNullable), Dest: fmt.Sprintf("%v", destNullable), Message: "column nullable mismatch"})
+			} else {
+				result.PassedCount++
+			}
+		}
+		if request.CheckDefaults {
+			sourceDefault, _ := defaultValue(source.Defaults[column])
+			destDefault, _ := defaultValue(dest.Defaults[column])
+			if !strings.EqualFold(sourceDefault, destDefault) {
+				result.addDifference(rules, &SchemaDifference{Kind: SchemaDiffDefault, Column: column, Source: sourceDefault, Dest: destDefault, Message: "column default mismatch"})
+			} else {
+				result.PassedCount++
+			}
+		}
+	}
+	var destColumns = make([]string, 0)
+	for _, column := range dest.Columns {
+		if rules.IgnoreColumn(source.Table, column) {
+			continue
+		}
+		if _, ok := source.ColumnTypes[column]; !ok {
+			result.addDifference(rules, &SchemaDifference{Kind: SchemaDiffExtraColumn, Column: column, Dest: dest.ColumnTypes[column], Message: "extra column in dest"})
+			continue
+		}
+		destColumns = append(destColumns, column)
+	}
+	if request.CheckColumnOrder {
+		checkColumnOrder(result, rules, sourceColumns, destColumns)
+	}
+	if request.CheckPrimaryKeys {
+		sourcePk, destPk := strings.Join(nonEmpty(source.PkColumns), ","), strings.Join(nonEmpty(dest.PkColumns), ",")
+		if !strings.EqualFold(sourcePk, destPk) {
+			result.addDifference(rules, &SchemaDifference{Kind: SchemaDiffPrimaryKey, Source: sourcePk, Dest: destPk, Message: "primary key mismatch"})
+		} else {
+			result.PassedCount++
+		}
+	}
+	if request.CheckIndexes {
+		compareSchemaObjects(result, rules, indexKeys(source.Indexes), indexKeys(dest.Indexes), "index")
+	}
+	if request.CheckForeignKeys {
+		compareSchemaObjects(result, rules, foreignKeyKeys(source.ForeignKeys), foreignKeyKeys(dest.ForeignKeys), "foreign key")
+	}
+	return result
+}
+
+// checkColumnOrder reports common columns on different positions
+func checkColumnOrder(result *SchemaTableCheck, rules *SchemaRules, sourceColumns, destColumns []string) {
+	var common = make(map[string]bool)
+	for _, column := range destColumns {
+		common[column] = true
+	}
+	position := 0
+	for _, column := range sourceColumns {
+		if !common[column] {
+			continue
+		}
+		if destColumns[position] != column {
+			result.addDifference(rules, &SchemaDifference{Kind: SchemaDiffColumnOrder, Column: column, Source: fmt.Sprintf("%v", position+1), Dest: destColumns[position], Message: "column order mismatch"})
+		} else {
+			result.PassedCount++
+		}
+		position++
+	}
+}
+
+// schemaObjectKey represents vendor neutral index or foreign key identity
+type schemaObjectKey struct {
+	key  string
+	name string
+	kind string
+}
+
+func indexKeys(indexes []*TableIndex) []*schemaObjectKey {
+	var result = make([]*schemaObjectKey, 0)
+	for _, index := range indexes {
+		kind := SchemaDiffIndex
+		if index.Unique {
+			kind = SchemaDiffUnique
+		}
+		result = append(result, &schemaObjectKey{key: kind + ":" + strings.ToLower(strings.Join(index.Columns, ",")), name: index.Name, kind: kind})
+	}
+	return result
+}
+
+func foreignKeyKeys(foreignKeys []*ForeignKey) []*schemaObjectKey {
+	var result = make([]*schemaObjectKey, 0)
+	for _, foreignKey := range foreignKeys {
+		key := fmt.Sprintf("(%v)->%v(%v)", strings.Join(foreignKey.Columns, ","), foreignKey.RefTable, strings.Join(foreignKey.RefColumns, ","))
+		result = append(result, &schemaObjectKey{key: strings.ToLower(key), name: foreignKey.Name, kind: SchemaDiffForeignKey})
+	}
+	return result
+}
+
+// compareSchemaObjects compares indexes or foreign keys by columns rather than names, which differ across vendors
+func compareSchemaObjects(result *SchemaTableCheck, rules *SchemaRules, source, dest []*schemaObjectKey, label string) {
+	var destKeys = make(map[string]*schemaObjectKey)
+	for _, object := range dest {
+		destKeys[object.key] = object
+	}
+	for _, object := range source {
+		if _, ok := destKeys[object.key]; ok {
+			result.PassedCount++
+			delete(destKeys, object.key)
+			continue
+		}
+		result.addDifference(rules, &SchemaDifference{Kind: object.kind, Column: object.name, Source: object.key, Message: "missing " + label + " in dest"})
+	}
+	for _, object := range dest {
+		if _, ok := destKeys[object.key]; ok {
+			result.addDifference(rules, &SchemaDifference{Kind: object.kind, Column: object.name, Dest: object.key, Message: "extra " + label + " in dest"})
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"github.com/viant/dsunit/script"
+	"testing"
+)
+
+func TestSchemaRules_EquivalentTypes(t *testing.T) {
+	rules := &SchemaRules{EquivalentTypes: []string{"INT ~ INTEGER ~ INT64", "VARCHAR(n) ~ STRING", "DECIMAL(p,s) ~ NUMERIC(p,s)"}}
+	if !assert.Nil(t, rules.Init()) {
+		return
+	}
+	var useCases = []struct {
+		type1  string
+		type2  string
+		expect bool
+	}{
+		{"int", "INT", true},
+		{"INT", "INTEGER", true},
+		{"INT(11)", "INT64", true},
+		{"VARCHAR(255)", "STRING", true},
+		{"VARCHAR(255)", "VARCHAR(64)", false},
+		{"DECIMAL(7, 2)", "NUMERIC(7,2)", true},
+		{"DECIMAL(7,2)", "NUMERIC(10,2)", false},
+		{"TEXT", "STRING", false},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, rules.Equivalent(useCase.type1, useCase.type2), useCase.type1+" ~ "+useCase.type2)
+	}
+	assert.NotNil(t, (&SchemaRules{EquivalentTypes: []string{"INT"}}).Init())
+}
+
+func TestCheckTableSchema(t *testing.T) {
+	source := &TableSchema{
+		TableDescriptor: &dsc.TableDescriptor{
+			Table:       "users",
+			PkColumns:   []string{"id"},
+			Columns:     []string{"id", "email", "name", "updated"},
+			ColumnTypes: map[string]string{"id": "INT", "email": "VARCHAR(255)", "name": "VARCHAR(64)", "updated": "TIMESTAMP"},
+			Nullables:   map[string]bool{"id": false, "email": false},
+		},
+		Defaults: map[string]string{"name": "'n/a'"},
+		Indexes:  []*TableIndex{{Name: "users_email", Table: "users", Columns: []string{"email"}, Unique: true}, {Name: "users_name", Table: "users", Columns: []string{"name"}}},
+	}
+	dest := &TableSchema{
+		TableDescriptor: &dsc.TableDescriptor{
+			Table:       "users",
+			PkColumns:   []string{"id"},
+			Columns:     []string{"id", "name", "email", "comments"},
+			ColumnTypes: map[string]string{"id": "INTEGER", "email": "STRING", "name": "TEXT", "comments": "TEXT"},
+			Nullables:   map[string]bool{"id": false, "email": true},
+		},
+		Indexes: []*TableIndex{{Name: "idx_1", Table: "users", Columns: []string{"email"}, Unique: true}},
+	}
+	request := &CheckSchemaRequest{
+		CheckNullables:   true,
+		CheckPrimaryKeys: true,
+		CheckIndexes:     true,
+		CheckDefaults:    true,
+		CheckColumnOrder: true,
+		Rules: &SchemaRules{
+			EquivalentTypes: []string{"INT ~ INTEGER", "VARCHAR(n) ~ STRING"},
+			IgnoreColumns:   []string{"users.updated"},
+			Severity:        map[string]string{SchemaDiffNullable: SeverityWarning, SchemaDiffExtraColumn: SeverityIgnore},
+		},
+	}
+	if !assert.Nil(t, request.Init()) {
+		return
+	}
+	check := checkTableSchema(request, source, dest)
+	var kinds = make(map[string]string)
+	for _, difference := range check.Differences {
+		kinds[difference.Kind+"/"+difference.Column] = difference.Severity
+	}
+	assert.EqualValues(t, map[string]string{
+		SchemaDiffColumnType + "/name":   SeverityError,
+		SchemaDiffNullable + "/email":    SeverityWarning,
+		SchemaDiffDefault + "/name":      SeverityWarning,
+		SchemaDiffColumnOrder + "/email": SeverityWarning,
+		SchemaDiffColumnOrder + "/name":  SeverityWarning,
+		SchemaDiffIndex + "/users_name":  SeverityWarning,
+	}, kinds)
+	assert.Equal(t, 1, check.Errors)
+	assert.Equal(t, 5, check.Warnings)
+	assert.Equal(t, 1, len(check.Failures))
+}
+
+func TestCheckTableSchema_DDLNullable(t *testing.T) {
+	tables, err := parseDDLSchema("CREATE TABLE users(id INT PRIMARY KEY, name VARCHAR(64))", script.DialectGeneric, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	dest := &TableSchema{
+		TableDescriptor: &dsc.TableDescriptor{
+			Table:       "users",
+			PkColumns:   []string{"id"},
+			Columns:     []string{"id", "name"},
+			ColumnTypes: map[string]string{"id": "INT", "name": "VARCHAR(64)"},
+			Nullables:   map[string]bool{"id": false, "name": false},
+		},
+	}
+	request := &CheckSchemaRequest{CheckNullables: true}
+	if !assert.Nil(t, request.Init()) {
+		return
+	}
+	check := checkTableSchema(request, tables["users"], dest)
+	if assert.Equal(t, 1, len(check.Differences)) {
+		assert.Equal(t, SchemaDiffNullable, check.Differences[0].Kind)
+		assert.Equal(t, "name", check.Differences[0].Column)
+		assert.Equal(t, "true", check.Differences[0].Source)
+	}
+	assert.Equal(t, 3, check.PassedCount)
+}
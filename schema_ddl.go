@@ -0,0 +1,206 @@
+package dsunit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ddlDialect represents target vendor DDL capabilities
+type ddlDialect struct {
+	primaryKey          bool
+	defaults            bool
+	indexes             bool
+	foreignKeys         bool //ALTER TABLE ADD CONSTRAINT FOREIGN KEY support
+	inlineForeignKeys   bool //foreign keys declared in CREATE TABLE only
+	sequences           bool
+	autoincrement       string
+	inlineAutoincrement bool   //INTEGER PRIMARY KEY AUTOINCREMENT
+	modifyColumn        bool   //MODIFY COLUMN with full column definition, used for type and nullability changes
+	alterColumnType     string //ALTER COLUMN type change clause, %[1]v column, %[2]v type, empty if not supported
+	setNotNull          bool   //ALTER COLUMN SET NOT NULL support
+	dropNotNull         bool   //ALTER COLUMN DROP NOT NULL support
+	dropPrimaryKey      string //DROP primary key clause, $table is replaced with table name, empty if not supported
+	addPrimaryKey       bool   //ALTER TABLE ADD PRIMARY KEY support
+	schema              string //fixed schema qualifier, current datastore is used if empty
+	indexSchema         bool   //schema qualifies index name rather than indexed table
+	multiRowInsert      bool   //INSERT INTO ... VALUES (...), (...) support
+	backslashEscape     bool   //backslash is an escape character in string literals
+	binaryLiteral       string //binary literal, %v is replaced with hex encoded value
+	timestampLiteral    string //timestamp literal, %v is replaced with quoted timestamp
+}
+
+var genericDDLDialect = &ddlDialect{primaryKey: true, defaults: true, indexes: true, foreignKeys: true, sequences: true,
+	alterColumnType: "ALTER COLUMN %[1]v TYPE %[2]v", setNotNull: true, dropNotNull: true, dropPrimaryKey: "DROP CONSTRAINT $table_pkey", addPrimaryKey: true,
+	binaryLiteral: "X'%v'", timestampLiteral: "TIMESTAMP %v"}
+
+var postgresDDLDialect = &ddlDialect{primaryKey: true, defaults: true, indexes: true, foreignKeys: true, sequences: true, autoincrement: " GENERATED BY DEFAULT AS IDENTITY",
+	alterColumnType: "ALTER COLUMN %[1]v TYPE %[2]v", setNotNull: true, dropNotNull: true, dropPrimaryKey: "DROP CONSTRAINT $table_pkey", addPrimaryKey: true,
+	multiRowInsert: true, binaryLiteral: "decode('%v', 'hex')", timestampLiteral: "%v"}
+
+var ddlDialects = map[string]*ddlDialect{
+	"mysql": {primaryKey: true, defaults: true, indexes: true, foreignKeys: true, autoincrement: " AUTO_INCREMENT", modifyColumn: true, dropPrimaryKey: "DROP PRIMARY KEY", addPrimaryKey: true,
+		multiRowInsert: true, backslashEscape: true, binaryLiteral: "X'%v'", timestampLiteral: "%v"},
+	"postgres": postgresDDLDialect,
+	"pgx":      postgresDDLDialect,
+	"sqlite3": {primaryKey: true, defaults: true, indexes: true, inlineForeignKeys: true, inlineAutoincrement: true, schema: "main", indexSchema: true,
+		multiRowInsert: true, binaryLiteral: "X'%v'", timestampLiteral: "%v"},
+	"bigquery": {alterColumnType: "ALTER COLUMN %[1]v SET DATA TYPE %[2]v", dropNotNull: true,
+		multiRowInsert: true, backslashEscape: true, binaryLiteral: "FROM_HEX('%v')", timestampLiteral: "TIMESTAMP %v"},
+}
+
+func getDDLDialect(driver string) *ddlDialect {
+	if dialect, ok := ddlDialects[driver]; ok {
+		return dialect
+	}
+	return genericDDLDialect
+}
+
+// schemaName returns schema qualifier for supplied datastore
+func (d *ddlDialect) schemaName(datastore string) string {
+	if d.schema != "" {
+		return d.schema
+	}
+	return datastore
+}
+
+var numericLiteralExpr = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+var castExpr = regexp.MustCompile(`::[\w ]+(\[\])?$`)
+
+// defaultValue returns portable column default expression, false if default should be skipped
+func defaultValue(value string) (string, bool) {
+	value = strings.TrimSpace(castExpr.ReplaceAllString(strings.TrimSpace(value), ""))
+	upper := strings.ToUpper(value)
+	switch upper {
+	case "", "NULL":
+		return "", false
+	case "CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP()", "NOW()", "LOCALTIMESTAMP", "LOCALTIMESTAMP()":
+		return "CURRENT_TIMESTAMP", true
+	case "CURRENT_DATE", "CURRENT_DATE()", "CURDATE()":
+		return "CURRENT_DATE", true
+	case "TRUE", "FALSE":
+		return upper, true
+	}
+	if isSequenceDefault(value) {
+		return "", false
+	}
+	if numericLiteralExpr.MatchString(value) || strings.HasPrefix(value, "'") || strings.HasPrefix(value, "(") || strings.HasPrefix(upper, "B'") {
+		return value, true
+	}
+	return "'" + strings.Replace(value, "'", "''", -1) + "'", true
+}
+
+// createTable returns CREATE TABLE DDL
+func (d *ddlDialect) createTable(datastore string, table *TableSchema) string {
+	var ddlColumns = []string{}
+	var pk = table.PkColumns
+	inlineAutoincrement := d.inlineAutoincrement && table.Autoincrement && len(pk) == 1
+	for _, columnName := range table.Columns {
+		var ddlColumn = "\t" + columnName
+		dbType := table.ColumnTypes[columnName]
+		if inlineAutoincrement && columnName == pk[0] {
+			ddlColumns = append(ddlColumns, ddlColumn+" INTEGER PRIMARY KEY AUTOINCREMENT")
+			continue
+		}
+		ddlColumn += " " + dbType
+		if value, ok := table.Defaults[columnName]; ok && d.defaults {
+			if value, ok = defaultValue(value); ok {
+				ddlColumn += " DEFAULT " + value
+			}
+		}
+		if nullable, ok := table.Nullables[columnName]; ok && !nullable {
+			ddlColumn += " NOT NULL"
+		}
+		if table.Autoincrement && len(pk) == 1 && columnName == pk[0] {
+			ddlColumn += d.autoincrement
+		}
+		ddlColumns = append(ddlColumns, ddlColumn)
+	}
+	if d.primaryKey && len(pk) > 0 && !inlineAutoincrement {
+		ddlColumns = append(ddlColumns, fmt.Sprintf("\tPRIMARY KEY(%v)", strings.Join(pk, ", ")))
+	}
+	if d.inlineForeignKeys {
+		for _, foreignKey := range table.ForeignKeys {
+			ddlColumns = append(ddlColumns, "\t"+foreignKeyConstraint(foreignKey))
+		}
+	}
+	return fmt.Sprintf("CREATE TABLE %v.%v(\n%v);\n", strings.ToLower(datastore), table.Table, strings.Join(ddlColumns, ",\n"))
+}
+
+func foreignKeyConstraint(foreignKey *ForeignKey) string {
+	var refColumns = ""
+	if len(foreignKey.RefColumns) > 0 {
+		refColumns = "(" + strings.Join(foreignKey.RefColumns, ", ") + ")"
+	}
+	result := fmt.Sprintf("CONSTRAINT %v FOREIGN KEY (%v) REFERENCES %v%v", foreignKey.Name, strings.Join(foreignKey.Columns, ", "), foreignKey.RefTable, refColumns)
+	if foreignKey.OnDelete != "" {
+		result += " ON DELETE " + foreignKey.OnDelete
+	}
+	return result
+}
+
+// createIndex returns CREATE INDEX DDL
+func (d *ddlDialect) createIndex(datastore string, index *TableIndex) string {
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+	datastore = strings.ToLower(datastore)
+	if d.indexSchema {
+		return fmt.Sprintf("CREATE %vINDEX %v.%v ON %v(%v);", unique, datastore, index.Name, index.Table, strings.Join(index.Columns, ", "))
+	}
+	return fmt.Sprintf("CREATE %vINDEX %v ON %v.%v(%v);", unique, index.Name, datastore, index.Table, strings.Join(index.Columns, ", "))
+}
+
+// addForeignKey returns ALTER TABLE ADD CONSTRAINT DDL
+func (d *ddlDialect) addForeignKey(datastore string, foreignKey *ForeignKey) string {
+	return fmt.Sprintf("ALTER TABLE %v.%v ADD %v;", strings.ToLower(datastore), foreignKey.Table, foreignKeyConstraint(foreignKey))
+}
+
+// createView returns CREATE VIEW DDL
+func (d *ddlDialect) createView(datastore string, view *View) string {
+	return fmt.Sprintf("CREATE VIEW %v.%v AS %v;", strings.ToLower(datastore), view.Name, view.SQL)
+}
+
+// createSequence returns CREATE SEQUENCE DDL
+func (d *ddlDialect) createSequence(sequence *Sequence) string {
+	result := "CREATE SEQUENCE " + sequence.Name
+	if sequence.Start != 0 {
+		result += fmt.Sprintf(" START WITH %v", sequence.Start)
+	}
+	if sequence.Increment != 0 && sequence.Increment != 1 {
+		result += fmt.Sprintf(" INCREMENT BY %v", sequence.Increment)
+	}
+	return result + ";"
+}
+
+// schemaDDLs returns DDLs ordered to replay cleanly: sequences, tables, indexes, foreign keys and views last
+func (d *ddlDialect) schemaDDLs(datastore string, sequences []*Sequence, tables []*TableSchema, views []*View) []string {
+	var result = make([]string, 0)
+	if d.sequences {
+		for _, sequence := range sequences {
+			result = append(result, d.createSequence(sequence))
+		}
+	}
+	for _, table := range tables {
+		result = append(result, d.createTable(datastore, table))
+	}
+	if d.indexes {
+		for _, table := range tables {
+			for _, index := range table.Indexes {
+				result = append(result, d.createIndex(datastore, index))
+			}
+		}
+	}
+	if d.foreignKeys {
+		for _, table := range tables {
+			for _, foreignKey := range table.ForeignKeys {
+				result = append(result, d.addForeignKey(datastore, foreignKey))
+			}
+		}
+	}
+	for _, view := range views {
+		result = append(result, d.createView(datastore, view))
+	}
+	return result
+}
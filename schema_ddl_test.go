@@ -0,0 +1,116 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestDDLDialect_SchemaDDLs(t *testing.T) {
+	users := &TableSchema{
+		TableDescriptor: &dsc.TableDescriptor{
+			Table:         "users",
+			Autoincrement: true,
+			PkColumns:     []string{"id"},
+			Columns:       []string{"id", "email", "active", "created"},
+			ColumnTypes:   map[string]string{"id": "INT", "email": "VARCHAR(255)", "active": "TINYINT", "created": "TIMESTAMP"},
+			Nullables:     map[string]bool{"id": false, "email": false},
+		},
+		Defaults: map[string]string{"active": "1", "created": "now()", "email": "n/a"},
+		Indexes:  []*TableIndex{{Name: "users_email", Table: "users", Columns: []string{"email"}, Unique: true}},
+	}
+	orders := &TableSchema{
+		TableDescriptor: &dsc.TableDescriptor{
+			Table:       "orders",
+			PkColumns:   []string{"id"},
+			Columns:     []string{"id", "user_id"},
+			ColumnTypes: map[string]string{"id": "INT", "user_id": "INT"},
+		},
+		ForeignKeys: []*ForeignKey{{Name: "fk_orders_user", Table: "orders", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE"}},
+	}
+	views := []*View{{Name: "active_users", SQL: "SELECT * FROM users WHERE active = 1"}}
+	sequences := []*Sequence{{Name: "order_seq", Start: 100, Increment: 1}}
+
+	var useCases = []struct {
+		description string
+		target      string
+		expect      []string
+	}{
+		{
+			description: "mysql",
+			target:      "mysql",
+			expect: []string{
+				"CREATE TABLE db1.users(\n\tid INT NOT NULL AUTO_INCREMENT,\n\temail VARCHAR(255) DEFAULT 'n/a' NOT NULL,\n\tactive TINYINT DEFAULT 1,\n\tcreated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,\n\tPRIMARY KEY(id));\n",
+				"CREATE TABLE db1.orders(\n\tid INT,\n\tuser_id INT,\n\tPRIMARY KEY(id));\n",
+				"CREATE UNIQUE INDEX users_email ON db1.users(email);",
+				"ALTER TABLE db1.orders ADD CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;",
+				"CREATE VIEW db1.active_users AS SELECT * FROM users WHERE active = 1;",
+			},
+		},
+		{
+			description: "sqlite",
+			target:      "sqlite3",
+			expect: []string{
+				"CREATE TABLE db1.users(\n\tid INTEGER PRIMARY KEY AUTOINCREMENT,\n\temail VARCHAR(255) DEFAULT 'n/a' NOT NULL,\n\tactive TINYINT DEFAULT 1,\n\tcreated TIMESTAMP DEFAULT CURRENT_TIMESTAMP);\n",
+				"CREATE TABLE db1.orders(\n\tid INT,\n\tuser_id INT,\n\tPRIMARY KEY(id),\n\tCONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE);\n",
+				"CREATE UNIQUE INDEX db1.users_email ON users(email);",
+				"CREATE VIEW db1.active_users AS SELECT * FROM users WHERE active = 1;",
+			},
+		},
+		{
+			description: "postgres",
+			target:      "postgres",
+			expect: []string{
+				"CREATE SEQUENCE order_seq START WITH 100;",
+				"CREATE TABLE db1.users(\n\tid INT NOT NULL GENERATED BY DEFAULT AS IDENTITY,\n\temail VARCHAR(255) DEFAULT 'n/a' NOT NULL,\n\tactive TINYINT DEFAULT 1,\n\tcreated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,\n\tPRIMARY KEY(id));\n",
+				"CREATE TABLE db1.orders(\n\tid INT,\n\tuser_id INT,\n\tPRIMARY KEY(id));\n",
+				"CREATE UNIQUE INDEX users_email ON db1.users(email);",
+				"ALTER TABLE db1.orders ADD CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;",
+				"CREATE VIEW db1.active_users AS SELECT * FROM users WHERE active = 1;",
+			},
+		},
+		{
+			description: "bigquery",
+			target:      "bigquery",
+			expect: []string{
+				"CREATE TABLE db1.users(\n\tid INT NOT NULL,\n\temail VARCHAR(255) NOT NULL,\n\tactive TINYINT,\n\tcreated TIMESTAMP);\n",
+				"CREATE TABLE db1.orders(\n\tid INT,\n\tuser_id INT);\n",
+				"CREATE VIEW db1.active_users AS SELECT * FROM users WHERE active = 1;",
+			},
+		},
+	}
+	for _, useCase := range useCases {
+		actual := getDDLDialect(useCase.target).schemaDDLs("DB1", sequences, []*TableSchema{users, orders}, views)
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
+
+func TestOrderViews(t *testing.T) {
+	views := orderViews([]*View{
+		{Name: "v_report", SQL: "SELECT * FROM v_users u JOIN orders o ON o.user_id = u.id"},
+		{Name: "v_users", SQL: "SELECT * FROM users"},
+	})
+	assert.Equal(t, "v_users", views[0].Name)
+	assert.Equal(t, "v_report", views[1].Name)
+}
+
+func TestDumpedViews(t *testing.T) {
+	views := dumpedViews([]*View{
+		{Name: "v_report", SQL: "SELECT * FROM v_users u JOIN orders o ON o.user_id = u.id"},
+		{Name: "v_users", SQL: "SELECT * FROM users"},
+		{Name: "v_products", SQL: "SELECT * FROM products"},
+		{Name: "v_audit", SQL: "SELECT * FROM audit"},
+	}, []string{"USERS", "v_audit"})
+	var names = make([]string, 0)
+	for _, view := range views {
+		names = append(names, view.Name)
+	}
+	assert.EqualValues(t, []string{"v_report", "v_users", "v_audit"}, names)
+}
+
+func TestReferencedSequences(t *testing.T) {
+	sequences := referencedSequences([]*Sequence{{Name: "order_seq"}, {Name: "user_seq"}, {Name: "seq"}}, []string{"nextval('public.order_seq'::regclass)", "CREATE TABLE users(id INT)"})
+	if assert.Equal(t, 1, len(sequences)) {
+		assert.Equal(t, "order_seq", sequences[0].Name)
+	}
+}
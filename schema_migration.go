@@ -0,0 +1,108 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"strings"
+)
+
+// columnDefinition returns column type with nullability
+func columnDefinition(table *dsc.TableDescriptor, column string) string {
+	result := table.ColumnTypes[column]
+	if nullable, ok := table.Nullables[column]; ok && !nullable {
+		result += " NOT NULL"
+	}
+	return result
+}
+
+func (d *ddlDialect) alterTable(datastore, table, clause string) string {
+	return fmt.Sprintf("ALTER TABLE %v.%v %v;", strings.ToLower(datastore), table, clause)
+}
+
+func unsupportedDDL(table, change string) string {
+	return fmt.Sprintf("%v %v: %v is not supported by target dialect, table rebuild required", unsupportedDDLPrefix, table, change)
+}
+
+// unsupportedDDLPrefix marks migration warnings emitted as SQL comments instead of unsupported statements
+const unsupportedDDLPrefix = "--"
+
+// isUnsupportedDDL returns true if DDL is unsupported change warning
+func isUnsupportedDDL(DDL string) bool {
+	return strings.HasPrefix(DDL, unsupportedDDLPrefix)
+}
+
+// migrateTable returns DDLs altering dest table columns, nullability and primary key to match source table
+func (d *ddlDialect) migrateTable(datastore string, source, dest *dsc.TableDescriptor, request *CheckSchemaRequest) []string {
+	var result = make([]string, 0)
+	table := dest.Table
+	for _, column := range source.Columns {
+		if request.Rules.IgnoreColumn(table, column) {
+			continue
+		}
+		destType, ok := dest.ColumnTypes[column]
+		if !ok {
+			result = append(result, d.alterTable(datastore, table, fmt.Sprintf("ADD COLUMN %v %v", column, columnDefinition(source, column))))
+			continue
+		}
+		typeChanged := !request.Rules.Equivalent(source.ColumnTypes[column], destType)
+		sourceNullable, hasSourceNullable := source.Nullables[column]
+		destNullable, hasDestNullable := dest.Nullables[column]
+		nullableChanged := request.CheckNullables && hasSourceNullable && hasDestNullable && sourceNullable != destNullable
+		if !typeChanged && !nullableChanged {
+			continue
+		}
+		if d.modifyColumn {
+			result = append(result, d.alterTable(datastore, table, fmt.Sprintf("MODIFY COLUMN %v %v", column, columnDefinition(source, column))))
+			continue
+		}
+		if typeChanged {
+			if d.alterColumnType == "" {
+				result = append(result, unsupportedDDL(table, fmt.Sprintf("%v type change %v -> %v", column, destType, source.ColumnTypes[column])))
+			} else {
+				result = append(result, d.alterTable(datastore, table, fmt.Sprintf(d.alterColumnType, column, source.ColumnTypes[column])))
+			}
+		}
+		if nullableChanged {
+			switch {
+			case sourceNullable && d.dropNotNull:
+				result = append(result, d.alterTable(datastore, table, fmt.Sprintf("ALTER COLUMN %v DROP NOT NULL", column)))
+			case !sourceNullable && d.setNotNull:
+				result = append(result, d.alterTable(datastore, table, fmt.Sprintf("ALTER COLUMN %v SET NOT NULL", column)))
+			default:
+				result = append(result, unsupportedDDL(table, fmt.Sprintf("%v nullability change", column)))
+			}
+		}
+	}
+	var sourceColumns = make(map[string]bool)
+	for _, column := range source.Columns {
+		sourceColumns[column] = true
+	}
+	for _, column := range dest.Columns {
+		if !sourceColumns[column] && !request.Rules.IgnoreColumn(table, column) {
+			result = append(result, d.alterTable(datastore, table, "DROP COLUMN "+column))
+		}
+	}
+	if request.CheckPrimaryKeys && d.primaryKey {
+		sourcePk, destPk := nonEmpty(source.PkColumns), nonEmpty(dest.PkColumns)
+		if strings.Join(sourcePk, ",") != strings.Join(destPk, ",") {
+			if len(destPk) > 0 {
+				if d.dropPrimaryKey == "" {
+					return append(result, unsupportedDDL(table, "primary key change"))
+				}
+				result = append(result, d.alterTable(datastore, table, strings.Replace(d.dropPrimaryKey, "$table", table, 1)))
+			}
+			if len(sourcePk) > 0 {
+				if !d.addPrimaryKey {
+					return append(result, unsupportedDDL(table, "primary key change"))
+				}
+				result = append(result, d.alterTable(datastore, table, fmt.Sprintf("ADD PRIMARY KEY(%v)", strings.Join(sourcePk, ", "))))
+			}
+		}
+	}
+	return result
+}
+
+// dropTable returns DROP TABLE DDL
+func (d *ddlDialect) dropTable(datastore, table string) string {
+	return fmt.Sprintf("DROP TABLE %v.%v;", strings.ToLower(datastore), table)
+}
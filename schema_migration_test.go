@@ -0,0 +1,73 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestDDLDialect_MigrateTable(t *testing.T) {
+	source := &dsc.TableDescriptor{
+		Table:       "users",
+		PkColumns:   []string{"id"},
+		Columns:     []string{"id", "name", "email", "salary"},
+		ColumnTypes: map[string]string{"id": "INT", "name": "VARCHAR(255)", "email": "VARCHAR(255)", "salary": "DECIMAL(7,2)"},
+		Nullables:   map[string]bool{"id": false, "name": false, "email": true, "salary": true},
+	}
+	dest := &dsc.TableDescriptor{
+		Table:       "users",
+		PkColumns:   []string{"id", "name"},
+		Columns:     []string{"id", "name", "salary", "comments"},
+		ColumnTypes: map[string]string{"id": "INT", "name": "VARCHAR(255)", "salary": "FLOAT", "comments": "TEXT"},
+		Nullables:   map[string]bool{"id": false, "name": true, "salary": true, "comments": true},
+	}
+	request := &CheckSchemaRequest{CheckNullables: true, CheckPrimaryKeys: true}
+	var useCases = []struct {
+		description string
+		target      string
+		expect      []string
+	}{
+		{
+			description: "mysql",
+			target:      "mysql",
+			expect: []string{
+				"ALTER TABLE db1.users MODIFY COLUMN name VARCHAR(255) NOT NULL;",
+				"ALTER TABLE db1.users ADD COLUMN email VARCHAR(255);",
+				"ALTER TABLE db1.users MODIFY COLUMN salary DECIMAL(7,2);",
+				"ALTER TABLE db1.users DROP COLUMN comments;",
+				"ALTER TABLE db1.users DROP PRIMARY KEY;",
+				"ALTER TABLE db1.users ADD PRIMARY KEY(id);",
+			},
+		},
+		{
+			description: "postgres",
+			target:      "postgres",
+			expect: []string{
+				"ALTER TABLE db1.users ALTER COLUMN name SET NOT NULL;",
+				"ALTER TABLE db1.users ADD COLUMN email VARCHAR(255);",
+				"ALTER TABLE db1.users ALTER COLUMN salary TYPE DECIMAL(7,2);",
+				"ALTER TABLE db1.users DROP COLUMN comments;",
+				"ALTER TABLE db1.users DROP CONSTRAINT users_pkey;",
+				"ALTER TABLE db1.users ADD PRIMARY KEY(id);",
+			},
+		},
+		{
+			description: "sqlite",
+			target:      "sqlite3",
+			expect: []string{
+				"-- users: name nullability change is not supported by target dialect, table rebuild required",
+				"ALTER TABLE db1.users ADD COLUMN email VARCHAR(255);",
+				"-- users: salary type change FLOAT -> DECIMAL(7,2) is not supported by target dialect, table rebuild required",
+				"ALTER TABLE db1.users DROP COLUMN comments;",
+				"-- users: primary key change is not supported by target dialect, table rebuild required",
+			},
+		},
+	}
+	for _, useCase := range useCases {
+		actual := getDDLDialect(useCase.target).migrateTable("db1", source, dest, request)
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+	dest = &dsc.TableDescriptor{Table: "users", Columns: source.Columns, ColumnTypes: source.ColumnTypes, Nullables: source.Nullables}
+	assert.EqualValues(t, []string{"-- users: primary key change is not supported by target dialect, table rebuild required"}, getDDLDialect("sqlite3").migrateTable("main", source, dest, request))
+	assert.EqualValues(t, []string{"ALTER TABLE db1.users ADD PRIMARY KEY(id);"}, getDDLDialect("postgres").migrateTable("db1", source, dest, request))
+}
@@ -0,0 +1,341 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/dsunit/script"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var createTableExpr = regexp.MustCompile(`(?is)^CREATE\s+(?:OR\s+REPLACE\s+)?(?:(?:GLOBAL\s+|LOCAL\s+)?TEMP(?:ORARY)?\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)\s*\((.*)\)[^)]*$`)
+var createIndexExpr = regexp.MustCompile(`(?is)^CREATE\s+(UNIQUE\s+)?INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)\s+ON\s+([^\s(]+)\s*(?:USING\s+\w+\s*)?\((.*)\)[^)]*$`)
+var alterTableAddExpr = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:ONLY\s+)?([^\s(]+)\s+ADD\s+(.+)$`)
+
+// columnTypeTerminators represents keywords ending column type declaration
+var columnTypeTerminators = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true, "UNIQUE": true, "REFERENCES": true, "CHECK": true,
+	"AUTO_INCREMENT": true, "AUTOINCREMENT": true, "COLLATE": true, "CHARACTER": true, "CHARSET": true, "CONSTRAINT": true,
+	"COMMENT": true, "GENERATED": true, "IDENTITY": true, "ON": true, "OPTIONS": true, "KEY": true,
+}
+
+var serialTypes = map[string]string{"SERIAL": "INTEGER", "BIGSERIAL": "BIGINT", "SMALLSERIAL": "SMALLINT"}
+
+// unquoteIdentifier removes identifier quotes and schema prefix
+func unquoteIdentifier(name string) string {
+	name = strings.TrimSpace(name)
+	if index := strings.LastIndex(name, "."); index != -1 {
+		name = name[index+1:]
+	}
+	return strings.Trim(name, "`\"[]")
+}
+
+// splitTopLevel splits text by separator outside parentheses and quotes
+func splitTopLevel(text string, separator rune) []string {
+	var result = make([]string, 0)
+	var depth = 0
+	var quote rune
+	var start = 0
+	for i, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == separator && depth == 0:
+			result = append(result, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(text[start:]); last != "" {
+		result = append(result, last)
+	}
+	return result
+}
+
+// tokenizeDefinition splits column or constraint definition into words, quoted literals and parenthesized groups
+func tokenizeDefinition(text string) []string {
+	var result = make([]string, 0)
+	var token = make([]rune, 0)
+	var depth = 0
+	var quote rune
+	flush := func() {
+		if len(token) > 0 {
+			result = append(result, string(token))
+			token = token[:0]
+		}
+	}
+	for _, r := range text {
+		switch {
+		case quote != 0:
+			token = append(token, r)
+			if r == quote && depth == 0 {
+				quote = 0
+				flush()
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			token = append(token, r)
+		case r == '(':
+			if depth == 0 && len(token) > 0 && !isIdentifierPrefix(token) {
+				flush()
+			}
+			depth++
+			token = append(token, r)
+		case r == ')':
+			depth--
+			token = append(token, r)
+			if depth == 0 {
+				flush()
+			}
+		case unicode.IsSpace(r) && depth == 0:
+			flush()
+		default:
+			token = append(token, r)
+		}
+	}
+	flush()
+	return result
+}
+
+// isIdentifierPrefix returns true if token is a function or type name followed by parameters i.e. now(), VARCHAR(255)
+func isIdentifierPrefix(token []rune) bool {
+	for _, r := range token {
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// parenthesizedList returns unquoted identifiers listed in (a, b)
+func parenthesizedList(token string) []string {
+	token = strings.TrimSpace(token)
+	if index := strings.Index(token, "("); index != -1 {
+		token = token[index+1:]
+	}
+	token = strings.TrimSuffix(token, ")")
+	var result = make([]string, 0)
+	for _, item := range splitTopLevel(token, ',') {
+		fields := strings.Fields(item) //skip ASC/DESC and length modifiers
+		if len(fields) > 0 {
+			result = append(result, unquoteIdentifier(strings.Split(fields[0], "(")[0]))
+		}
+	}
+	return result
+}
+
+// ddlSchemaParser builds table schemas from DDL statements
+type ddlSchemaParser struct {
+	mapper *typeMapper
+	tables map[string]*TableSchema
+}
+
+func newDDLSchemaParser(mapper *typeMapper) *ddlSchemaParser {
+	return &ddlSchemaParser{mapper: mapper, tables: make(map[string]*TableSchema)}
+}
+
+// parse parses CREATE TABLE, CREATE INDEX and ALTER TABLE ADD constraint statements, other statements are skipped
+func (p *ddlSchemaParser) parse(statement string) error {
+	statement = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(statement), ";"))
+	if matched := createTableExpr.FindStringSubmatch(statement); len(matched) > 0 {
+		return p.parseCreateTable(unquoteIdentifier(matched[1]), matched[2])
+	}
+	if matched := createIndexExpr.FindStringSubmatch(statement); len(matched) > 0 {
+		table, ok := p.tables[unquoteIdentifier(matched[3])]
+		if !ok {
+			return fmt.Errorf("unknown table %v in: %v", matched[3], statement)
+		}
+		table.Indexes = append(table.Indexes, &TableIndex{Name: unquoteIdentifier(matched[2]), Table: table.Table, Columns: parenthesizedList(matched[4]), Unique: matched[1] != ""})
+		return nil
+	}
+	if matched := alterTableAddExpr.FindStringSubmatch(statement); len(matched) > 0 {
+		table, ok := p.tables[unquoteIdentifier(matched[1])]
+		if !ok {
+			return fmt.Errorf("unknown table %v in: %v", matched[1], statement)
+		}
+		tokens := tokenizeDefinition(matched[2])
+		if len(tokens) > 1 && strings.EqualFold(tokens[0], "COLUMN") {
+			tokens = tokens[1:]
+		}
+		if !p.parseConstraint(table, tokens) && len(tokens) > 1 {
+			p.parseColumn(table, tokens)
+		}
+	}
+	return nil
+}
+
+func (p *ddlSchemaParser) parseCreateTable(name, body string) error {
+	table := &TableSchema{
+		TableDescriptor: &dsc.TableDescriptor{Table: name, ColumnTypes: make(map[string]string), Columns: make([]string, 0), Nullables: make(map[string]bool), PkColumns: make([]string, 0)},
+		Defaults:        make(map[string]string),
+		Indexes:         make([]*TableIndex, 0),
+		ForeignKeys:     make([]*ForeignKey, 0),
+	}
+	for _, definition := range splitTopLevel(body, ',') {
+		tokens := tokenizeDefinition(definition)
+		if len(tokens) == 0 {
+			continue
+		}
+		if !p.parseConstraint(table, tokens) {
+			p.parseColumn(table, tokens)
+		}
+	}
+	for _, column := range table.PkColumns {
+		table.Nullables[column] = false
+	}
+	p.tables[name] = table
+	return nil
+}
+
+// splitListToken splits token into identifier and parenthesized list i.e. idx(a, b)
+func splitListToken(token string) (string, string) {
+	if index := strings.Index(token, "("); index != -1 {
+		return token[:index], token[index:]
+	}
+	return token, ""
+}
+
+// parseConstraint parses table level constraint, returns false if definition is not a constraint
+func (p *ddlSchemaParser) parseConstraint(table *TableSchema, tokens []string) bool {
+	var name string
+	if strings.EqualFold(tokens[0], "CONSTRAINT") && len(tokens) > 2 {
+		name, tokens = unquoteIdentifier(tokens[1]), tokens[2:]
+	}
+	keyword, list := splitListToken(tokens[0])
+	keyword = strings.ToUpper(keyword)
+	switch keyword {
+	case "PRIMARY", "UNIQUE", "KEY", "INDEX", "FOREIGN":
+	case "CHECK", "FULLTEXT", "SPATIAL", "EXCLUDE":
+		return true
+	default:
+		return false
+	}
+	var i = 1
+	for ; list == "" && i < len(tokens); i++ {
+		var prefix string
+		prefix, list = splitListToken(tokens[i])
+		if upper := strings.ToUpper(prefix); prefix != "" && upper != "KEY" && upper != "INDEX" && name == "" && keyword != "PRIMARY" && keyword != "FOREIGN" {
+			name = unquoteIdentifier(prefix)
+		}
+	}
+	columns := parenthesizedList(list)
+	switch keyword {
+	case "PRIMARY":
+		table.PkColumns = columns
+		for _, column := range columns {
+			table.Nullables[column] = false
+		}
+	case "FOREIGN":
+		foreignKey := &ForeignKey{Name: name, Table: table.Table, Columns: columns}
+		p.parseReferences(foreignKey, tokens[i:])
+		if foreignKey.Name == "" {
+			foreignKey.Name = fmt.Sprintf("fk_%v_%v", table.Table, strings.Join(columns, "_"))
+		}
+		table.ForeignKeys = append(table.ForeignKeys, foreignKey)
+	default:
+		if name == "" {
+			name = fmt.Sprintf("%v_%v_idx", table.Table, strings.Join(columns, "_"))
+		}
+		table.Indexes = append(table.Indexes, &TableIndex{Name: name, Table: table.Table, Columns: columns, Unique: keyword == "UNIQUE"})
+	}
+	return true
+}
+
+// parseReferences parses REFERENCES table(columns) [ON DELETE action]
+func (p *ddlSchemaParser) parseReferences(foreignKey *ForeignKey, tokens []string) {
+	for i := 0; i < len(tokens); i++ {
+		switch strings.ToUpper(tokens[i]) {
+		case "REFERENCES":
+			if i+1 < len(tokens) {
+				refTable := tokens[i+1]
+				if index := strings.Index(refTable, "("); index != -1 {
+					foreignKey.RefColumns = parenthesizedList(refTable[index:])
+					refTable = refTable[:index]
+				} else if i+2 < len(tokens) && strings.HasPrefix(tokens[i+2], "(") {
+					foreignKey.RefColumns = parenthesizedList(tokens[i+2])
+				}
+				foreignKey.RefTable = unquoteIdentifier(refTable)
+			}
+		case "DELETE":
+			if i > 0 && strings.EqualFold(tokens[i-1], "ON") && i+1 < len(tokens) {
+				action := strings.ToUpper(tokens[i+1])
+				if (action == "SET" || action == "NO") && i+2 < len(tokens) {
+					action += " " + strings.ToUpper(tokens[i+2])
+				}
+				if action != "NO ACTION" && action != "RESTRICT" {
+					foreignKey.OnDelete = action
+				}
+			}
+		}
+	}
+}
+
+func (p *ddlSchemaParser) parseColumn(table *TableSchema, tokens []string) {
+	column := unquoteIdentifier(tokens[0])
+	var typeTokens = make([]string, 0)
+	i := 1
+	for ; i < len(tokens) && (i == 1 || !columnTypeTerminators[strings.ToUpper(tokens[i])]); i++ {
+		typeTokens = append(typeTokens, tokens[i])
+	}
+	dbType := normalizeType(strings.Join(typeTokens, " "))
+	if baseType, ok := serialTypes[dbType]; ok {
+		dbType = baseType
+		table.Autoincrement = true
+	}
+	table.Columns = append(table.Columns, column)
+	table.ColumnTypes[column] = p.mapper.Map(column, dbType)
+	table.Nullables[column] = true
+	for ; i < len(tokens); i++ {
+		switch strings.ToUpper(tokens[i]) {
+		case "NOT":
+			if i+1 < len(tokens) && strings.EqualFold(tokens[i+1], "NULL") {
+				table.Nullables[column] = false
+				i++
+			}
+		case "NULL":
+			table.Nullables[column] = true
+		case "DEFAULT":
+			if i+1 < len(tokens) {
+				if isSequenceDefault(tokens[i+1]) {
+					table.Autoincrement = true
+				} else {
+					table.Defaults[column] = tokens[i+1]
+				}
+				i++
+			}
+		case "PRIMARY":
+			table.PkColumns = []string{column}
+			table.Nullables[column] = false
+		case "UNIQUE":
+			table.Indexes = append(table.Indexes, &TableIndex{Name: fmt.Sprintf("%v_%v_idx", table.Table, column), Table: table.Table, Columns: []string{column}, Unique: true})
+		case "AUTO_INCREMENT", "AUTOINCREMENT", "IDENTITY":
+			table.Autoincrement = true
+		case "REFERENCES":
+			foreignKey := &ForeignKey{Name: fmt.Sprintf("fk_%v_%v", table.Table, column), Table: table.Table, Columns: []string{column}}
+			p.parseReferences(foreignKey, tokens[i:])
+			table.ForeignKeys = append(table.ForeignKeys, foreignKey)
+			return
+		}
+	}
+}
+
+// parseDDLSchema returns table schemas defined by DDL script, statements are split and stripped of comments with dialect lexing rules
+func parseDDLSchema(DDL string, dialect script.Dialect, mapper *typeMapper) (map[string]*TableSchema, error) {
+	parser := newDDLSchemaParser(mapper)
+	for _, statement := range script.ParseWithoutComments(DDL, dialect) {
+		if err := parser.parse(statement); err != nil {
+			return nil, err
+		}
+	}
+	return parser.tables, nil
+}
@@ -0,0 +1,70 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsunit/script"
+	"strings"
+	"testing"
+)
+
+func TestParseDDLSchema(t *testing.T) {
+	statements := []string{
+		"DROP TABLE IF EXISTS users",
+		"CREATE TABLE `users` (\n" +
+			"  `id`       INT(11) NOT NULL AUTO_INCREMENT,\n" +
+			"  `email`    VARCHAR(255) NOT NULL, -- login\n" +
+			"  `salary`   DECIMAL(7, 2) DEFAULT 0.0,\n" +
+			"  `active`   TINYINT(1) DEFAULT '1',\n" +
+			"  `created`  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,\n" +
+			"  PRIMARY KEY(`id`),\n" +
+			"  UNIQUE KEY `users_email` (`email`),\n" +
+			"  KEY idx_created(created)\n" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8",
+		`CREATE TABLE public.orders (
+  id         SERIAL PRIMARY KEY,
+  user_id    integer REFERENCES users(id) ON DELETE CASCADE,
+  status     character varying(32) NOT NULL DEFAULT 'new--paid', /* workflow state */
+  total      numeric(10,2),
+  CONSTRAINT orders_status_check CHECK (status IN ('new', 'paid'))
+)`,
+		"CREATE INDEX orders_user ON orders (user_id)",
+		"ALTER TABLE orders ADD CONSTRAINT fk_orders_users FOREIGN KEY (user_id) REFERENCES users (id)",
+		"ALTER TABLE orders ADD COLUMN note TEXT",
+	}
+	mapper, err := getTypeMapper("", "mysql", "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	tables, err := parseDDLSchema(strings.Join(statements, ";\n"), script.DialectGeneric, mapper)
+	if !assert.Nil(t, err) {
+		return
+	}
+	users := tables["users"]
+	if assert.NotNil(t, users) {
+		assert.EqualValues(t, []string{"id", "email", "salary", "active", "created"}, users.Columns)
+		assert.EqualValues(t, map[string]string{"id": "INT", "email": "VARCHAR(255)", "salary": "DECIMAL(7,2)", "active": "TINYINT(1)", "created": "TIMESTAMP"}, users.ColumnTypes)
+		assert.EqualValues(t, map[string]bool{"id": false, "email": false, "salary": true, "active": true, "created": true}, users.Nullables)
+		assert.EqualValues(t, []string{"id"}, users.PkColumns)
+		assert.True(t, users.Autoincrement)
+		assert.EqualValues(t, map[string]string{"salary": "0.0", "active": "'1'", "created": "CURRENT_TIMESTAMP"}, users.Defaults)
+		assert.EqualValues(t, []*TableIndex{
+			{Name: "users_email", Table: "users", Columns: []string{"email"}, Unique: true},
+			{Name: "idx_created", Table: "users", Columns: []string{"created"}},
+		}, users.Indexes)
+	}
+	orders := tables["orders"]
+	if assert.NotNil(t, orders) {
+		assert.EqualValues(t, []string{"id", "user_id", "status", "total", "note"}, orders.Columns)
+		assert.EqualValues(t, map[string]string{"id": "INT", "user_id": "INT", "status": "VARCHAR(32)", "total": "DECIMAL(10,2)", "note": "TEXT"}, orders.ColumnTypes)
+		assert.True(t, orders.Autoincrement)
+		assert.EqualValues(t, []string{"id"}, orders.PkColumns)
+		assert.EqualValues(t, map[string]string{"status": "'new--paid'"}, orders.Defaults)
+		assert.EqualValues(t, []*TableIndex{{Name: "orders_user", Table: "orders", Columns: []string{"user_id"}}}, orders.Indexes)
+		assert.EqualValues(t, []*ForeignKey{
+			{Name: "fk_orders_user_id", Table: "orders", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE"},
+			{Name: "fk_orders_users", Table: "orders", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}},
+		}, orders.ForeignKeys)
+	}
+	_, err = parseDDLSchema("CREATE INDEX x ON missing(id)", script.DialectGeneric, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,437 @@
+package script
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Dialect represents SQL script lexing rules
+type Dialect int
+
+const (
+	// DialectGeneric splits on ; and MySQL DELIMITER, it supports $$ quoted bodies, BEGIN ... END blocks and backslash escapes
+	DialectGeneric Dialect = iota
+	// DialectMySQL supports DELIMITER, # comments and backslash escapes
+	DialectMySQL
+	// DialectPostgreSQL supports tagged dollar quotes and E'...' escape strings
+	DialectPostgreSQL
+	// DialectOracle supports SQL*Plus / terminator and PL/SQL units
+	DialectOracle
+	// DialectSQLServer supports GO batch separator and [identifiers]
+	DialectSQLServer
+)
+
+var driverDialects = map[string]Dialect{
+	"mysql":      DialectMySQL,
+	"postgres":   DialectPostgreSQL,
+	"postgresql": DialectPostgreSQL,
+	"pgx":        DialectPostgreSQL,
+	"pq":         DialectPostgreSQL,
+	"oracle":     DialectOracle,
+	"ora":        DialectOracle,
+	"oci8":       DialectOracle,
+	"godror":     DialectOracle,
+	"sqlserver":  DialectSQLServer,
+	"mssql":      DialectSQLServer,
+}
+
+// DialectForDriver returns dialect for supplied driver name, generic dialect is used for unknown drivers
+func DialectForDriver(driver string) Dialect {
+	return driverDialects[strings.ToLower(strings.TrimSpace(driver))]
+}
+
+// dialectRules represents dialect lexing rules
+type dialectRules struct {
+	delimiter       bool //MySQL client DELIMITER command
+	dollarQuote     bool //$tag$ ... $tag$ quoted text
+	backslash       bool //backslash escapes in quoted text
+	escapeString    bool //E'...' strings with backslash escapes
+	hashComment     bool //# line comments
+	bracketQuote    bool //[identifier]
+	slashTerminator bool //SQL*Plus / line terminator
+	goSeparator     bool //GO batch separator line
+	plSQLUnits      bool //PL/SQL units with declarations before BEGIN
+}
+
+var dialectsRules = map[Dialect]*dialectRules{
+	DialectGeneric:    {delimiter: true, dollarQuote: true, backslash: true},
+	DialectMySQL:      {delimiter: true, backslash: true, hashComment: true},
+	DialectPostgreSQL: {dollarQuote: true, escapeString: true},
+	DialectOracle:     {slashTerminator: true, plSQLUnits: true},
+	DialectSQLServer:  {bracketQuote: true, goSeparator: true},
+}
+
+var (
+	slashLineExpr   = regexp.MustCompile(`(?m)^[ \t]*/[ \t]*\r?$`)
+	goLineExpr      = regexp.MustCompile(`(?im)^[ \t]*GO([ \t]+\d+)?[ \t]*\r?$`)
+	dollarTagExpr   = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+	nonBlockBegins  = map[string]bool{"TRANSACTION": true, "TRAN": true, "WORK": true, "ISOLATION": true, "DISTRIBUTED": true, "DEFERRED": true, "IMMEDIATE": true, "EXCLUSIVE": true}
+	nonBlockEnds    = map[string]bool{"IF": true, "LOOP": true, "WHILE": true, "REPEAT": true, "FOR": true}
+	blockPrecedents = map[string]bool{"": true, ";": true, "AS": true, "IS": true, "THEN": true, "ELSE": true, "LOOP": true, "DECLARE": true}
+	routineKinds    = map[string]bool{"TRIGGER": true, "PROCEDURE": true, "FUNCTION": true, "EVENT": true}
+)
+
+const (
+	blockUnit   = "block"   //PL/SQL unit ending with its top level BEGIN ... END block
+	packageUnit = "package" //PL/SQL unit ending with unmatched END
+)
+
+// lexer splits SQL script into statements
+type lexer struct {
+	rules       *dialectRules
+	input       string
+	pos         int
+	terminator  string
+	batches     bool //statements only end with separator line
+	sqlPlus     bool //PL/SQL units only end with / line
+	lineStarts  []int
+	text        strings.Builder
+	start       int
+	words       []string
+	lastToken   string //previous significant token, upper case word or punctuation
+	blocks      []string
+	blockClosed bool
+	unit        string
+	unitEnded   bool
+	noComments  bool //block comments are skipped like line comments
+	result      []*Statement
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b == '$' || b == '#' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || b >= utf8.RuneSelf
+}
+
+func isWordStart(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || b >= utf8.RuneSelf
+}
+
+// location returns 1-based line and column of supplied offset
+func (l *lexer) location(offset int) (int, int) {
+	index := sort.SearchInts(l.lineStarts, offset+1) - 1
+	return index + 1, utf8.RuneCountInString(l.input[l.lineStarts[index]:offset]) + 1
+}
+
+// emit appends text to the current statement, offset marks statement start if text is significant
+func (l *lexer) emit(text string, offset int) {
+	if l.start == -1 && strings.TrimSpace(text) != "" {
+		l.start = offset
+	}
+	l.text.WriteString(text)
+}
+
+// flush appends the current statement to result and resets statement state
+func (l *lexer) flush() {
+	if SQL := strings.TrimSpace(l.text.String()); SQL != "" {
+		line, column := l.location(l.start)
+		l.result = append(l.result, &Statement{SQL: SQL, Line: line, Column: column})
+	}
+	l.text.Reset()
+	l.start = -1
+	l.words = nil
+	l.lastToken = ""
+	l.blocks = nil
+	l.blockClosed = false
+	l.unit = ""
+	l.unitEnded = false
+}
+
+// separatorLine returns separator line size if the current line is GO or / separator
+func (l *lexer) separatorLine() int {
+	if l.pos > 0 && l.input[l.pos-1] != '\n' {
+		return 0
+	}
+	end := strings.IndexByte(l.input[l.pos:], '\n')
+	size := end + 1
+	if end == -1 {
+		end = len(l.input) - l.pos
+		size = end
+	}
+	line := strings.TrimSpace(l.input[l.pos : l.pos+end])
+	if l.rules.slashTerminator && line == "/" {
+		return size
+	}
+	if l.rules.goSeparator && goLineExpr.MatchString(line) {
+		return size
+	}
+	return 0
+}
+
+// holdsTerminator returns true if ; does not end the current statement
+func (l *lexer) holdsTerminator() bool {
+	switch {
+	case l.batches:
+		return true
+	case l.unit != "" && l.sqlPlus:
+		return true
+	case l.unit == packageUnit:
+		return !l.unitEnded
+	case l.unit == blockUnit && !l.blockClosed:
+		return true
+	}
+	return len(l.blocks) > 0
+}
+
+// nextWord returns upper case word following supplied offset, or the first non space character
+func (l *lexer) nextWord(offset int) string {
+	for offset < len(l.input) && strings.IndexByte(" \t\r\n", l.input[offset]) != -1 {
+		offset++
+	}
+	end := offset
+	for end < len(l.input) && isIdentifierByte(l.input[end]) {
+		end++
+	}
+	if end == offset && end < len(l.input) {
+		end++
+	}
+	return strings.ToUpper(l.input[offset:end])
+}
+
+// detectUnit sets PL/SQL unit kind from statement leading words
+func (l *lexer) detectUnit() {
+	if !l.rules.plSQLUnits || l.unit != "" || len(l.words) == 0 {
+		return
+	}
+	if l.words[0] == "DECLARE" {
+		l.unit = blockUnit
+		return
+	}
+	if l.words[0] != "CREATE" {
+		return
+	}
+	for i, word := range l.words[1:] {
+		switch word {
+		case "OR", "REPLACE", "EDITIONABLE", "NONEDITIONABLE":
+			continue
+		case "PROCEDURE", "FUNCTION", "TRIGGER":
+			l.unit = blockUnit
+		case "PACKAGE":
+			l.unit = packageUnit
+		case "TYPE":
+			if i+2 < len(l.words) && l.words[i+2] == "BODY" {
+				l.unit = packageUnit
+			}
+		}
+		return
+	}
+}
+
+// isRoutine returns true if statement creates trigger, procedure, function or event
+func (l *lexer) isRoutine() bool {
+	if len(l.words) == 0 || l.words[0] != "CREATE" {
+		return false
+	}
+	for _, word := range l.words[1:] {
+		if routineKinds[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// opensBlock returns true if BEGIN at the current position starts a block rather than being i.e. a column name
+func (l *lexer) opensBlock() bool {
+	if blockPrecedents[l.lastToken] {
+		return true
+	}
+	return l.isRoutine() && l.lastToken != "(" && l.lastToken != ","
+}
+
+// atLineStart returns true if only white spaces precede the current position in its line
+func (l *lexer) atLineStart() bool {
+	for i := l.pos - 1; i >= 0 && l.input[i] != '\n'; i-- {
+		if l.input[i] != ' ' && l.input[i] != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// keyword tracks blocks, CASE expressions and PL/SQL units, it returns number of bytes consumed after the word
+func (l *lexer) keyword(word string) int {
+	if len(l.words) < 6 {
+		l.words = append(l.words, word)
+		l.detectUnit()
+	}
+	if l.terminator != ";" {
+		return 0
+	}
+	switch word {
+	case "BEGIN":
+		if !l.opensBlock() {
+			return 0
+		}
+		next := l.nextWord(l.pos)
+		if next != "" && !nonBlockBegins[next] && strings.IndexAny(next, ";,.=)") == -1 {
+			l.blocks = append(l.blocks, word)
+		}
+	case "CASE":
+		l.blocks = append(l.blocks, word)
+	case "END":
+		next := l.nextWord(l.pos)
+		if nonBlockEnds[next] {
+			return 0
+		}
+		if len(l.blocks) == 0 {
+			if l.unit == packageUnit {
+				l.unitEnded = true
+			}
+			return 0
+		}
+		opening := l.blocks[len(l.blocks)-1]
+		l.blocks = l.blocks[:len(l.blocks)-1]
+		if opening == "BEGIN" && len(l.blocks) == 0 {
+			l.blockClosed = true
+		}
+		if next == "CASE" {
+			return strings.Index(strings.ToUpper(l.input[l.pos:]), next) + len(next)
+		}
+	}
+	return 0
+}
+
+// quoted returns end offset of quoted text starting at the current position
+func (l *lexer) quoted(closing byte, backslash bool) int {
+	for i := l.pos + 1; i < len(l.input); i++ {
+		if backslash && l.input[i] == '\\' {
+			i++
+			continue
+		}
+		if l.input[i] == closing {
+			return i + 1
+		}
+	}
+	return len(l.input)
+}
+
+// next processes token at the current position
+func (l *lexer) next() {
+	input := l.input[l.pos:]
+	current := input[0]
+	previous := byte(' ')
+	if l.pos > 0 {
+		previous = l.input[l.pos-1]
+	}
+	switch {
+	case strings.HasPrefix(input, "--") || (l.rules.hashComment && current == '#'):
+		end := strings.IndexByte(input, '\n')
+		if end == -1 {
+			end = len(input) - 1
+		}
+		l.pos += end + 1
+		return
+	case strings.HasPrefix(input, "/*"):
+		end := strings.Index(input[2:], "*/")
+		size := len(input)
+		if end != -1 {
+			size = end + 4
+		}
+		if l.noComments {
+			l.emit(" ", l.pos)
+			l.pos += size
+			return
+		}
+		l.emit(input[:size], l.pos)
+		l.pos += size
+		return
+	case strings.HasPrefix(input, l.terminator):
+		if l.terminator == ";" && l.holdsTerminator() {
+			break
+		}
+		if l.terminator == ";" && (l.blockClosed || l.unitEnded) {
+			l.emit(l.terminator, l.pos)
+		}
+		l.pos += len(l.terminator)
+		l.flush()
+		return
+	case current == '\'':
+		backslash := l.rules.backslash || (l.rules.escapeString && (previous == 'E' || previous == 'e') && (l.pos < 2 || !isIdentifierByte(l.input[l.pos-2])))
+		end := l.quoted(current, backslash)
+		l.emit(l.input[l.pos:end], l.pos)
+		l.pos = end
+		return
+	case current == '"' || current == '`' || (l.rules.bracketQuote && current == '['):
+		closing := current
+		if current == '[' {
+			closing = ']'
+		}
+		end := l.quoted(closing, l.rules.backslash && current == '"')
+		l.emit(l.input[l.pos:end], l.pos)
+		l.pos = end
+		return
+	case current == '$' && l.rules.dollarQuote && !isIdentifierByte(previous):
+		if tag := dollarTagExpr.FindString(input); tag != "" {
+			size := len(input)
+			if end := strings.Index(input[len(tag):], tag); end != -1 {
+				size = len(tag) + end + len(tag)
+			}
+			l.emit(input[:size], l.pos)
+			l.pos += size
+			return
+		}
+	case isWordStart(current) && !isIdentifierByte(previous):
+		end := 1
+		for end < len(input) && isIdentifierByte(input[end]) && !strings.HasPrefix(input[end:], l.terminator) {
+			end++
+		}
+		word := strings.ToUpper(input[:end])
+		if l.rules.delimiter && word == "DELIMITER" && l.atLineStart() {
+			lineEnd := strings.IndexByte(input, '\n')
+			if lineEnd == -1 {
+				lineEnd = len(input)
+			}
+			if delimiter := strings.TrimSpace(input[end:lineEnd]); delimiter != "" {
+				l.flush()
+				l.terminator = delimiter
+				l.pos += lineEnd
+				return
+			}
+		}
+		l.emit(input[:end], l.pos)
+		l.pos += end
+		consumed := l.keyword(word)
+		if consumed > 0 {
+			l.emit(l.input[l.pos:l.pos+consumed], l.pos)
+			l.pos += consumed
+		}
+		l.lastToken = word
+		return
+	}
+	if strings.TrimSpace(input[:1]) != "" {
+		l.lastToken = input[:1]
+	}
+	l.emit(input[:1], l.pos)
+	l.pos++
+}
+
+// statements returns script statements
+func (l *lexer) statements() []*Statement {
+	for l.pos < len(l.input) {
+		if size := l.separatorLine(); size > 0 {
+			l.pos += size
+			l.flush()
+			continue
+		}
+		l.next()
+	}
+	l.flush()
+	return l.result
+}
+
+// newLexer creates a lexer for supplied script and dialect
+func newLexer(expression string, dialect Dialect) *lexer {
+	rules, ok := dialectsRules[dialect]
+	if !ok {
+		rules = dialectsRules[DialectGeneric]
+	}
+	result := &lexer{rules: rules, input: expression, terminator: ";", start: -1, lineStarts: []int{0}, result: make([]*Statement, 0)}
+	for i := 0; i < len(expression); i++ {
+		if expression[i] == '\n' {
+			result.lineStarts = append(result.lineStarts, i+1)
+		}
+	}
+	result.batches = rules.goSeparator && goLineExpr.MatchString(expression)
+	result.sqlPlus = rules.slashTerminator && slashLineExpr.MatchString(expression)
+	return result
+}
@@ -0,0 +1,13 @@
+package script
+
+// Statement represents SQL command with its 1-based source line and column
+type Statement struct {
+	SQL    string
+	Line   int
+	Column int
+}
+
+// ParseStatements splits SQL blob into separate commands with their source location
+func ParseStatements(expression string, dialect Dialect) []*Statement {
+	return newLexer(expression, dialect).statements()
+}
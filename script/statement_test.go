@@ -0,0 +1,259 @@
+package script
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseStatements(t *testing.T) {
+	SQL := `CREATE TABLE users (
+  id INT
+);
+
+INSERT INTO users(id) VALUES(1);
+  INSERT INTO users(id) VALUES(2);`
+	actual := ParseStatements(SQL, DialectGeneric)
+	assert.EqualValues(t, []*Statement{
+		{SQL: "CREATE TABLE users (\n  id INT\n)", Line: 1, Column: 1},
+		{SQL: "INSERT INTO users(id) VALUES(1)", Line: 5, Column: 1},
+		{SQL: "INSERT INTO users(id) VALUES(2)", Line: 6, Column: 3},
+	}, actual)
+}
+
+func TestParseWithDialect(t *testing.T) {
+	var useCases = []struct {
+		description string
+		dialect     Dialect
+		SQL         string
+		SQLs        []string
+	}{
+		{
+			description: "block comment and quotes with semicolons",
+			dialect:     DialectGeneric,
+			SQL: `/* first; second */ SELECT 'a;b', 'it''s;', "c;d" FROM t;
+SELECT 'x\';' FROM t;`,
+			SQLs: []string{
+				`/* first; second */ SELECT 'a;b', 'it''s;', "c;d" FROM t`,
+				`SELECT 'x\';' FROM t`,
+			},
+		},
+		{
+			description: "mysql delimiter adjacent to END",
+			dialect:     DialectMySQL,
+			SQL: `DELIMITER $$
+CREATE TRIGGER users_bi BEFORE INSERT ON users FOR EACH ROW
+BEGIN
+  SET NEW.name = UPPER(NEW.name);
+END$$
+DELIMITER ;
+SELECT 2;`,
+			SQLs: []string{
+				`CREATE TRIGGER users_bi BEFORE INSERT ON users FOR EACH ROW
+BEGIN
+  SET NEW.name = UPPER(NEW.name);
+END`,
+				`SELECT 2`,
+			},
+		},
+		{
+			description: "generic slash delimiter adjacent to END",
+			dialect:     DialectGeneric,
+			SQL: `DELIMITER //
+CREATE PROCEDURE p()
+BEGIN
+  SELECT 1;
+END//
+DELIMITER ;
+SELECT 2;`,
+			SQLs: []string{
+				`CREATE PROCEDURE p()
+BEGIN
+  SELECT 1;
+END`,
+				`SELECT 2`,
+			},
+		},
+		{
+			description: "begin column name",
+			dialect:     DialectGeneric,
+			SQL:         "CREATE TABLE t (begin INT, x INT);\nINSERT INTO t(begin, x) VALUES(1, 2);\nSELECT 3;",
+			SQLs: []string{
+				`CREATE TABLE t (begin INT, x INT)`,
+				`INSERT INTO t(begin, x) VALUES(1, 2)`,
+				`SELECT 3`,
+			},
+		},
+		{
+			description: "sqlite trigger block",
+			dialect:     DialectGeneric,
+			SQL:         "CREATE TRIGGER users_ai AFTER INSERT ON users BEGIN\n  UPDATE stats SET cnt = cnt + 1;\nEND;\nSELECT 1;",
+			SQLs: []string{
+				"CREATE TRIGGER users_ai AFTER INSERT ON users BEGIN\n  UPDATE stats SET cnt = cnt + 1;\nEND;",
+				`SELECT 1`,
+			},
+		},
+		{
+			description: "mysql hash comment",
+			dialect:     DialectMySQL,
+			SQL: `# setup; users
+INSERT INTO users(name) VALUES('a\';b');
+SELECT 1;`,
+			SQLs: []string{
+				`INSERT INTO users(name) VALUES('a\';b')`,
+				`SELECT 1`,
+			},
+		},
+		{
+			description: "postgres tagged dollar quote",
+			dialect:     DialectPostgreSQL,
+			SQL: `CREATE FUNCTION inc(i INT) RETURNS INT AS $body$
+BEGIN
+  RETURN i + 1;
+END;
+$body$ LANGUAGE plpgsql;
+SELECT inc(1);`,
+			SQLs: []string{
+				`CREATE FUNCTION inc(i INT) RETURNS INT AS $body$
+BEGIN
+  RETURN i + 1;
+END;
+$body$ LANGUAGE plpgsql`,
+				`SELECT inc(1)`,
+			},
+		},
+		{
+			description: "postgres escape string",
+			dialect:     DialectPostgreSQL,
+			SQL:         `SELECT E'a\';b', 'c\'; SELECT 2;`,
+			SQLs: []string{
+				`SELECT E'a\';b', 'c\'`,
+				`SELECT 2`,
+			},
+		},
+		{
+			description: "postgres transaction",
+			dialect:     DialectPostgreSQL,
+			SQL:         "BEGIN;\nUPDATE t SET a = CASE WHEN b THEN 1 ELSE 2 END;\nCOMMIT;",
+			SQLs: []string{
+				`BEGIN`,
+				`UPDATE t SET a = CASE WHEN b THEN 1 ELSE 2 END`,
+				`COMMIT`,
+			},
+		},
+		{
+			description: "sql server batches",
+			dialect:     DialectSQLServer,
+			SQL: `CREATE PROCEDURE p AS
+BEGIN
+  SELECT [a;b] FROM t;
+  SELECT 2;
+END
+GO
+SELECT 3;
+go 2
+`,
+			SQLs: []string{
+				`CREATE PROCEDURE p AS
+BEGIN
+  SELECT [a;b] FROM t;
+  SELECT 2;
+END`,
+				`SELECT 3;`,
+			},
+		},
+		{
+			description: "sql server without batches",
+			dialect:     DialectSQLServer,
+			SQL:         "SELECT 1;\nSELECT 2;",
+			SQLs:        []string{`SELECT 1`, `SELECT 2`},
+		},
+		{
+			description: "oracle sql*plus terminator",
+			dialect:     DialectOracle,
+			SQL: `CREATE TABLE t (id NUMBER);
+CREATE OR REPLACE PROCEDURE p IS
+  v NUMBER;
+BEGIN
+  v := 1;
+END;
+/
+INSERT INTO t VALUES(1);
+`,
+			SQLs: []string{
+				`CREATE TABLE t (id NUMBER)`,
+				`CREATE OR REPLACE PROCEDURE p IS
+  v NUMBER;
+BEGIN
+  v := 1;
+END;`,
+				`INSERT INTO t VALUES(1)`,
+			},
+		},
+		{
+			description: "oracle package without terminator",
+			dialect:     DialectOracle,
+			SQL: `CREATE OR REPLACE PACKAGE BODY pkg AS
+  PROCEDURE a IS
+  BEGIN
+    IF 1 = 1 THEN
+      NULL;
+    END IF;
+  END a;
+END pkg;
+DECLARE
+  v NUMBER;
+BEGIN
+  v := 1;
+END;
+SELECT 1 FROM dual;`,
+			SQLs: []string{
+				`CREATE OR REPLACE PACKAGE BODY pkg AS
+  PROCEDURE a IS
+  BEGIN
+    IF 1 = 1 THEN
+      NULL;
+    END IF;
+  END a;
+END pkg;`,
+				`DECLARE
+  v NUMBER;
+BEGIN
+  v := 1;
+END;`,
+				`SELECT 1 FROM dual`,
+			},
+		},
+	}
+	for _, useCase := range useCases {
+		actual := ParseWithDialect(useCase.SQL, useCase.dialect)
+		assert.EqualValues(t, useCase.SQLs, actual, useCase.description)
+	}
+}
+
+func TestParseStatements_Location(t *testing.T) {
+	SQL := "-- users\nSELECT 'ł';  SELECT 2\nGO\n\n  SELECT 3"
+	actual := ParseStatements(SQL, DialectSQLServer)
+	assert.EqualValues(t, []*Statement{
+		{SQL: "SELECT 'ł';  SELECT 2", Line: 2, Column: 1},
+		{SQL: "SELECT 3", Line: 5, Column: 3},
+	}, actual)
+	actual = ParseStatements("SELECT 'ł'; SELECT 2", DialectGeneric)
+	assert.Equal(t, 1, actual[1].Line)
+	assert.Equal(t, 13, actual[1].Column)
+}
+
+func TestParseWithoutComments(t *testing.T) {
+	SQL := "/* users */ CREATE TABLE users(\n  name VARCHAR(8) DEFAULT 'a--b', -- name\n  note TEXT DEFAULT '/* n */'\n);\nSELECT 1 /* one */"
+	assert.EqualValues(t, []string{
+		"CREATE TABLE users(\n  name VARCHAR(8) DEFAULT 'a--b',   note TEXT DEFAULT '/* n */'\n)",
+		"SELECT 1",
+	}, ParseWithoutComments(SQL, DialectGeneric))
+}
+
+func TestDialectForDriver(t *testing.T) {
+	assert.Equal(t, DialectPostgreSQL, DialectForDriver("pgx"))
+	assert.Equal(t, DialectOracle, DialectForDriver("godror"))
+	assert.Equal(t, DialectSQLServer, DialectForDriver("sqlserver"))
+	assert.Equal(t, DialectMySQL, DialectForDriver("MySQL"))
+	assert.Equal(t, DialectGeneric, DialectForDriver("sqlite3"))
+}
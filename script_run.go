@@ -0,0 +1,214 @@
+package dsunit
+
+import (
+	"context"
+	"fmt"
+	"github.com/viant/afs"
+	"github.com/viant/dsc"
+	"github.com/viant/dsunit/script"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// transactionalDDLVendors represents vendors where DDL statements do not commit the current transaction
+var transactionalDDLVendors = map[string]bool{
+	"postgres":  true,
+	"sqlite":    true,
+	"sqlserver": true,
+	"mssql":     true,
+}
+
+var ddlKeywords = map[string]bool{
+	"CREATE":   true,
+	"ALTER":    true,
+	"DROP":     true,
+	"TRUNCATE": true,
+	"RENAME":   true,
+}
+
+// recordKeywords represents leading keywords of statements returning records
+var recordKeywords = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"VALUES":   true,
+	"CALL":     true,
+	"EXEC":     true,
+	"EXECUTE":  true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"EXPLAIN":  true,
+	"PRAGMA":   true,
+}
+
+var (
+	leadingCommentExpr = regexp.MustCompile(`^(\s*/\*(?s:.*?)\*/)*\s*\(*`)
+	returningExpr      = regexp.MustCompile(`(?i)\bRETURNING\b`)
+)
+
+// scriptStatement represents script statement with its source URL
+type scriptStatement struct {
+	URL string
+	*script.Statement
+}
+
+// location returns statement location as URL:line:column
+func (s *scriptStatement) location() string {
+	return fmt.Sprintf("%v:%v:%v", s.URL, s.Line, s.Column)
+}
+
+// isDDL returns true if statement starts with DDL keyword
+func isDDL(SQL string) bool {
+	fields := strings.Fields(SQL)
+	return len(fields) > 0 && ddlKeywords[strings.ToUpper(fields[0])]
+}
+
+// returnsRecords returns true if statement produces result set i.e. SELECT, CALL or DML with RETURNING clause
+func returnsRecords(SQL string) bool {
+	fields := strings.Fields(leadingCommentExpr.ReplaceAllString(SQL, ""))
+	if len(fields) == 0 {
+		return false
+	}
+	return recordKeywords[strings.ToUpper(strings.TrimRight(fields[0], ";"))] || returningExpr.MatchString(SQL)
+}
+
+// runStatement executes statement on supplied connection, records of result producing statement are read into the result
+func runStatement(manager dsc.Manager, connection dsc.Connection, result *StatementResult) (err error) {
+	startTime := time.Now()
+	defer func() {
+		result.ElapsedMs = int(time.Since(startTime) / time.Millisecond)
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}()
+	if returnsRecords(result.SQL) {
+		result.Records = make(Records, 0)
+		return manager.ReadAllOnConnection(connection, &result.Records, result.SQL, nil, nil)
+	}
+	sqlResult, err := manager.ExecuteOnConnection(connection, result.SQL, nil)
+	if err != nil {
+		return err
+	}
+	count, err := sqlResult.RowsAffected()
+	result.RowsAffected = int(count)
+	return err
+}
+
+// runSQL executes SQLs in one transaction, records of result producing statements are verified with expected records
+func (s *service) runSQL(request *RunSQLRequest, manager dsc.Manager, response *RunSQLResponse) error {
+	SQLs := s.expandSQLIfNeeded(request, manager)
+	for index := range request.Expect {
+		if !returnsRecords(SQLs[index]) {
+			return fmt.Errorf("expect was specified for statement %v not returning records: %v", index, SQLs[index])
+		}
+	}
+	connection, err := manager.ConnectionProvider().Get()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = connection.Close()
+	}()
+	if err = connection.Begin(); err != nil {
+		return err
+	}
+	response.Statements = make([]*StatementResult, 0, len(SQLs))
+	for _, SQL := range SQLs {
+		result := &StatementResult{SQL: SQL}
+		response.Statements = append(response.Statements, result)
+		if err = runStatement(manager, connection, result); err != nil {
+			_ = connection.Rollback()
+			return err
+		}
+		response.RowsAffected += result.RowsAffected
+	}
+	if err = connection.Commit(); err != nil {
+		return err
+	}
+	for i, result := range response.Statements {
+		expect, ok := request.Expect[i]
+		if !ok {
+			continue
+		}
+		if result.Validation, err = s.assertRecords(manager, NewDataset("sql", expect...), result.Records, request.CheckPolicy); err != nil {
+			return err
+		}
+		response.PassedCount += result.Validation.PassedCount
+		response.FailedCount += result.Validation.FailedCount
+	}
+	return nil
+}
+
+// readScriptStatements loads and parses request scripts with supplied dialect
+func readScriptStatements(request *RunScriptRequest, dialect script.Dialect) ([]*scriptStatement, error) {
+	var result = make([]*scriptStatement, 0)
+	var storageService = afs.New()
+	var ctx = context.Background()
+	for _, resource := range request.Scripts {
+		if err := resource.Init(); err != nil {
+			return nil, err
+		}
+		data, err := storageService.DownloadWithURL(ctx, resource.URL)
+		if err != nil {
+			return nil, err
+		}
+		for _, statement := range script.ParseStatements(string(data), dialect) {
+			result = append(result, &scriptStatement{URL: resource.URL, Statement: statement})
+		}
+	}
+	return result, nil
+}
+
+// runScript executes statements one by one on a single connection, transactional script is rolled back on the first error,
+// optional complete callback runs on the same connection after the last statement, before commit
+func (s *service) runScript(request *RunScriptRequest, manager dsc.Manager, statements []*scriptStatement, response *RunSQLResponse, complete func(connection dsc.Connection) error) (err error) {
+	if request.Transactional && !transactionalDDLVendors[typeVendor(manager.Config().DriverName)] {
+		for _, statement := range statements {
+			if isDDL(statement.SQL) {
+				return fmt.Errorf("%v: %v does not support transactional DDL", statement.location(), manager.Config().DriverName)
+			}
+		}
+	}
+	var SQLs = make([]string, 0, len(statements))
+	for _, statement := range statements {
+		SQLs = append(SQLs, statement.SQL)
+	}
+	SQLs = s.expandSQLIfNeeded(&RunSQLRequest{Expand: request.Expand, SQL: SQLs}, manager)
+	connection, err := manager.ConnectionProvider().Get()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = connection.Close()
+	}()
+	if request.Transactional {
+		if err = connection.Begin(); err != nil {
+			return err
+		}
+	}
+	response.Statements = make([]*StatementResult, 0, len(statements))
+	for i, statement := range statements {
+		result := &StatementResult{URL: statement.URL, Line: statement.Line, Column: statement.Column, SQL: SQLs[i]}
+		response.Statements = append(response.Statements, result)
+		if err = runStatement(manager, connection, result); err != nil {
+			if request.Transactional {
+				_ = connection.Rollback()
+			}
+			return fmt.Errorf("%v: %v", statement.location(), err)
+		}
+		response.RowsAffected += result.RowsAffected
+	}
+	if complete != nil {
+		if err = complete(connection); err != nil {
+			if request.Transactional {
+				_ = connection.Rollback()
+			}
+			return err
+		}
+	}
+	if request.Transactional {
+		return connection.Commit()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReturnsRecords(t *testing.T) {
+	var useCases = []struct {
+		SQL    string
+		expect bool
+	}{
+		{SQL: "SELECT 1", expect: true},
+		{SQL: "  select * FROM users", expect: true},
+		{SQL: "(SELECT 1) UNION (SELECT 2)", expect: true},
+		{SQL: "/* diagnostic; */ SELECT 1", expect: true},
+		{SQL: "WITH t AS (SELECT 1) SELECT * FROM t", expect: true},
+		{SQL: "CALL refresh_users(1)", expect: true},
+		{SQL: "EXEC dbo.refresh_users", expect: true},
+		{SQL: "INSERT INTO users(id) VALUES(1) RETURNING id", expect: true},
+		{SQL: "INSERT INTO users(id) VALUES(1)", expect: false},
+		{SQL: "DELETE FROM users", expect: false},
+		{SQL: "CREATE TABLE t(id INT)", expect: false},
+		{SQL: "", expect: false},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, returnsRecords(useCase.SQL), useCase.SQL)
+	}
+}
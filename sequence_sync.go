@@ -0,0 +1,154 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"sort"
+	"strings"
+)
+
+const (
+	sequenceMaxColumn  = "dsunit_max"
+	sequenceNameColumn = "dsunit_sequence"
+)
+
+// sequenceDialect represents vendor statements setting table sequence or identity next value,
+// %[1]v is replaced with table, %[2]v with key column, %[3]v with sequence, %[4]v with next value, %[5]v with max key value
+type sequenceDialect struct {
+	sequence string   //default sequence name, empty if table identity or autoincrement is synchronized
+	lookup   string   //query returning key column sequence name as sequenceNameColumn, NULL if column has no sequence
+	identity []string //statements used when sequence name is empty
+	named    []string //statements used with sequence name
+}
+
+var sequenceDialects = map[string]*sequenceDialect{
+	"postgres": {
+		lookup: "SELECT pg_get_serial_sequence('%[1]v', '%[2]v') AS " + sequenceNameColumn,
+		named:  []string{"SELECT setval('%[3]v', %[4]v, false)"},
+	},
+	"mysql": {
+		identity: []string{"ALTER TABLE %[1]v AUTO_INCREMENT = %[4]v"},
+	},
+	"sqlite": {
+		identity: []string{"DELETE FROM sqlite_sequence WHERE name = '%[1]v'", "INSERT INTO sqlite_sequence(name, seq) VALUES('%[1]v', %[5]v)"},
+	},
+	"oracle": {
+		identity: []string{"ALTER TABLE %[1]v MODIFY %[2]v GENERATED BY DEFAULT AS IDENTITY (START WITH %[4]v)"},
+		named:    []string{"ALTER SEQUENCE %[3]v RESTART START WITH %[4]v"},
+	},
+	"vertica": {
+		sequence: "%[1]v_%[2]v_seq",
+		named:    []string{"ALTER SEQUENCE %[3]v RESTART WITH %[4]v"},
+	},
+	"sqlserver": {
+		identity: []string{"DBCC CHECKIDENT ('%[1]v', RESEED, %[5]v)"},
+		named:    []string{"ALTER SEQUENCE %[3]v RESTART WITH %[4]v"},
+	},
+	"mssql": {
+		identity: []string{"DBCC CHECKIDENT ('%[1]v', RESEED, %[5]v)"},
+		named:    []string{"ALTER SEQUENCE %[3]v RESTART WITH %[4]v"},
+	},
+}
+
+// getSequenceDialect returns sequence dialect for supplied driver or nil if sequences are not supported
+func getSequenceDialect(driver string) *sequenceDialect {
+	return sequenceDialects[typeVendor(driver)]
+}
+
+// statements returns statements setting table sequence next value to max key value + 1
+func (d *sequenceDialect) statements(table, key, sequence string, maxValue int64) ([]string, error) {
+	templates := d.identity
+	if sequence == "" && d.sequence != "" {
+		sequence = fmt.Sprintf(d.sequence, table, key)
+	}
+	if sequence != "" {
+		templates = d.named
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("unable to sync %v sequence: named sequences are not supported", table)
+	}
+	var result = make([]string, 0, len(templates))
+	for _, template := range templates {
+		result = append(result, fmt.Sprintf(template, table, key, sequence, maxValue+1, maxValue))
+	}
+	return result, nil
+}
+
+// sequenceKey returns table single key column, empty if table has no or composite key
+func (s *service) sequenceKey(manager dsc.Manager, table string) string {
+	var keys []string
+	if descriptor := manager.TableDescriptorRegistry().Get(table); descriptor != nil {
+		keys = nonEmpty(descriptor.PkColumns)
+	}
+	if len(keys) == 0 {
+		dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+		datastore, _ := dialect.GetCurrentDatastore(manager)
+		keys = nonEmpty(strings.Split(dialect.GetKeyName(manager, datastore, table), ","))
+	}
+	if len(keys) != 1 {
+		return ""
+	}
+	return keys[0]
+}
+
+// syncSequences sets tables sequence or identity next value to max key value + 1, it returns next value by table and skipped tables,
+// tables without single column key or without key sequence are skipped unless sequence name is supplied
+func (s *service) syncSequences(manager dsc.Manager, tables []string, sequences map[string]string) (map[string]int, []string, error) {
+	var result = make(map[string]int)
+	var skipped = make([]string, 0)
+	dialect := getSequenceDialect(manager.Config().DriverName)
+	if dialect == nil {
+		return nil, nil, fmt.Errorf("sequence sync is not supported for %v", manager.Config().DriverName)
+	}
+	tables = append([]string{}, tables...)
+	sort.Strings(tables)
+	for _, table := range tables {
+		key := s.sequenceKey(manager, table)
+		if key == "" {
+			skipped = append(skipped, table)
+			continue
+		}
+		sequence := sequences[table]
+		if sequence == "" && dialect.lookup != "" {
+			var err error
+			if sequence, err = s.lookupSequence(manager, dialect, table, key); err != nil {
+				return nil, nil, err
+			}
+			if sequence == "" {
+				skipped = append(skipped, table)
+				continue
+			}
+		}
+		var records = make([]map[string]interface{}, 0)
+		SQL := fmt.Sprintf("SELECT COALESCE(MAX(%v), 0) AS %v FROM %v", key, sequenceMaxColumn, table)
+		if err := manager.ReadAll(&records, SQL, nil, nil); err != nil {
+			return nil, nil, fmt.Errorf("failed to read %v max %v: %v", table, key, err)
+		}
+		var maxValue int64
+		if len(records) > 0 {
+			maxValue = int64(toolbox.AsInt(records[0][sequenceMaxColumn]))
+		}
+		statements, err := dialect.statements(table, key, sequence, maxValue)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err = manager.ExecuteAll(statements); err != nil {
+			return nil, nil, fmt.Errorf("failed to sync %v sequence: %v", table, err)
+		}
+		result[table] = int(maxValue + 1)
+	}
+	return result, skipped, nil
+}
+
+// lookupSequence returns table key column sequence name, empty if column is neither serial nor identity column
+func (s *service) lookupSequence(manager dsc.Manager, dialect *sequenceDialect, table, key string) (string, error) {
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, fmt.Sprintf(dialect.lookup, table, key), nil, nil); err != nil {
+		return "", fmt.Errorf("failed to read %v.%v sequence: %v", table, key, err)
+	}
+	if len(records) == 0 || records[0][sequenceNameColumn] == nil {
+		return "", nil
+	}
+	return toolbox.AsString(records[0][sequenceNameColumn]), nil
+}
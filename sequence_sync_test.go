@@ -0,0 +1,71 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSequenceDialect_Statements(t *testing.T) {
+	var useCases = []struct {
+		description string
+		driver      string
+		sequence    string
+		expect      []string
+		hasError    bool
+	}{
+		{
+			description: "postgres serial",
+			driver:      "pgx",
+			hasError:    true,
+		},
+		{
+			description: "postgres named sequence",
+			driver:      "postgres",
+			sequence:    "users_seq",
+			expect:      []string{"SELECT setval('users_seq', 5, false)"},
+		},
+		{
+			description: "mysql autoincrement",
+			driver:      "mysql",
+			expect:      []string{"ALTER TABLE users AUTO_INCREMENT = 5"},
+		},
+		{
+			description: "sqlite autoincrement",
+			driver:      "sqlite3",
+			expect:      []string{"DELETE FROM sqlite_sequence WHERE name = 'users'", "INSERT INTO sqlite_sequence(name, seq) VALUES('users', 4)"},
+		},
+		{
+			description: "oracle named sequence",
+			driver:      "oci8",
+			sequence:    "users_seq",
+			expect:      []string{"ALTER SEQUENCE users_seq RESTART START WITH 5"},
+		},
+		{
+			description: "vertica default sequence",
+			driver:      "vertica",
+			expect:      []string{"ALTER SEQUENCE users_id_seq RESTART WITH 5"},
+		},
+		{
+			description: "mysql named sequence",
+			driver:      "mysql",
+			sequence:    "users_seq",
+			hasError:    true,
+		},
+	}
+	for _, useCase := range useCases {
+		dialect := getSequenceDialect(useCase.driver)
+		if !assert.NotNil(t, dialect, useCase.description) {
+			continue
+		}
+		actual, err := dialect.statements("users", "id", useCase.sequence, 4)
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, useCase.expect, actual, useCase.description)
+	}
+	assert.Nil(t, getSequenceDialect("bigquery"))
+	assert.Equal(t, "SELECT pg_get_serial_sequence('users', 'id') AS dsunit_sequence", fmt.Sprintf(getSequenceDialect("pgx").lookup, "users", "id"))
+}
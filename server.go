@@ -13,15 +13,20 @@ var registerURI = version + "register"
 var recreateURI = version + "recreate"
 var mappingURI = version + "mapping"
 var scriptURI = version + "script"
+var migrateURI = version + "migrate"
 var sqlURI = version + "sql"
 var schemaURI = version + "schema"
 var prepareURI = version + "prepare"
 var expectURI = version + "expect"
 var queryURI = version + "query"
 var freezeURI = version + "freeze"
+var scaffoldURI = version + "scaffold"
+var cloneURI = version + "clone"
 var dumpURI = version + "dump"
 var sequenceURI = version + "sequence"
+var syncSequencesURI = version + "syncSequences"
 var compareURI = version + "compare"
+var compareBatchURI = version + "compareBatch"
 
 var errorHandler = func(router *toolbox.ServiceRouter, responseWriter http.ResponseWriter, httpRequest *http.Request, message string) {
 	err := router.WriteResponse(toolbox.NewJSONEncoderFactory(), &BaseResponse{Status: "error", Message: message}, httpRequest, responseWriter)
@@ -64,6 +69,12 @@ func StartServer(port string) {
 			Handler:    service.RunScript,
 			Parameters: []string{"request"},
 		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        migrateURI,
+			Handler:    service.Migrate,
+			Parameters: []string{"request"},
+		},
 		toolbox.ServiceRouting{
 			HTTPMethod: "POST",
 			URI:        sqlURI,
@@ -94,12 +105,30 @@ func StartServer(port string) {
 			Handler:    service.Sequence,
 			Parameters: []string{"request"},
 		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        syncSequencesURI,
+			Handler:    service.SyncSequences,
+			Parameters: []string{"request"},
+		},
 		toolbox.ServiceRouting{
 			HTTPMethod: "POST",
 			URI:        freezeURI,
 			Handler:    service.Freeze,
 			Parameters: []string{"request"},
 		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        scaffoldURI,
+			Handler:    service.Scaffold,
+			Parameters: []string{"request"},
+		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        cloneURI,
+			Handler:    service.Clone,
+			Parameters: []string{"request"},
+		},
 		toolbox.ServiceRouting{
 			HTTPMethod: "POST",
 			URI:        dumpURI,
@@ -112,6 +141,12 @@ func StartServer(port string) {
 			Handler:    service.Compare,
 			Parameters: []string{"request"},
 		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        compareBatchURI,
+			Handler:    service.CompareBatch,
+			Parameters: []string{"request"},
+		},
 		toolbox.ServiceRouting{
 			HTTPMethod: "POST",
 			URI:        schemaURI,
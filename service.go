@@ -14,6 +14,7 @@ import (
 	_ "github.com/viant/scy/kms/blowfish"
 	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/data"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -36,6 +37,9 @@ type Service interface {
 	//Recreate remove and creates datastore
 	Recreate(request *RecreateRequest) *RecreateResponse
 
+	//Migrate runs pending versioned migration scripts and records them in tracking table
+	Migrate(request *MigrateRequest) *MigrateResponse
+
 	//RunSQL runs supplied SQL
 	RunSQL(request *RunSQLRequest) *RunSQLResponse
 
@@ -60,15 +64,27 @@ type Service interface {
 	//Sequence returns sequence for supplied tables
 	Sequence(request *SequenceRequest) *SequenceResponse
 
+	//SyncSequences sets supplied tables sequence or identity next value to max(pk)+1
+	SyncSequences(request *SyncSequencesRequest) *SyncSequencesResponse
+
 	//Freeze creates a dataset from existing database/datastore (reverse engineering test setup/verification)
 	Freeze(request *FreezeRequest) *FreezeResponse
 
+	//Scaffold creates prepare, expect datasets and go test skeleton from existing database
+	Scaffold(request *ScaffoldRequest) *ScaffoldResponse
+
+	//Clone copies tables schema and data from source to dest datastore, including across vendors
+	Clone(request *CloneRequest) *CloneResponse
+
 	//Dump creates a database schema from existing database for supplied tables, datastore
 	Dump(request *DumpRequest) *DumpResponse
 
 	//Compare compares data produces by specified SQLs
 	Compare(request *CompareRequest) *CompareResponse
 
+	//CompareBatch compares many named SQL pairs concurrently and summarizes results
+	CompareBatch(request *CompareBatchRequest) *CompareBatchResponse
+
 	//CheckSchema checks source and dest schema
 	CheckSchema(request *CheckSchemaRequest) *CheckSchemaResponse
 
@@ -83,6 +99,8 @@ type service struct {
 	mapper          *Mapper
 	context         toolbox.Context
 	adminDatastores map[string]string
+	sequenceBases   map[string]*sequenceBases
+	sequenceMux     sync.RWMutex
 }
 
 func (s *service) Registry() dsc.ManagerRegistry {
@@ -165,21 +183,12 @@ func (s *service) RunSQL(request *RunSQLRequest) *RunSQLResponse {
 		return response
 	}
 
-	manager := s.registry.Get(request.Datastore)
-	var SQL = s.expandSQLIfNeeded(request, manager)
-	results, err := manager.ExecuteAll(SQL)
-	if err != nil {
+	if err := request.Validate(); err != nil {
 		response.SetError(err)
 		return response
 	}
-	for _, result := range results {
-		count, err := result.RowsAffected()
-		if err != nil {
-			response.SetError(err)
-			return response
-		}
-		response.RowsAffected += int(count)
-	}
+	manager := s.registry.Get(request.Datastore)
+	response.SetError(s.runSQL(request, manager, response))
 	return response
 }
 
@@ -190,37 +199,38 @@ func (s *service) RunScript(request *RunScriptRequest) *RunSQLResponse {
 	if len(request.Scripts) == 0 {
 		return response
 	}
-	var SQL = []string{}
-	var err error
-	var storageService = afs.New()
-	var ctx = context.Background()
-	for _, resource := range request.Scripts {
-		err = resource.Init()
-		if err != nil {
-			break
-		}
-
-		var data []byte
-		if data, err = storageService.DownloadWithURL(ctx, resource.URL); err == nil {
-			if len(data) != 0 {
-				SQL = append(SQL, script.Parse(string(data))...)
-			}
-		}
-		if err != nil {
-			break
-		}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
 	}
-
+	manager := s.registry.Get(request.Datastore)
+	statements, err := readScriptStatements(request, script.DialectForDriver(manager.Config().DriverName))
 	if err != nil {
 		response.SetError(err)
 		return response
 	}
+	response.SetError(s.runScript(request, manager, statements, response, nil))
+	return response
+}
 
-	return s.RunSQL(&RunSQLRequest{
-		Expand:    request.Expand,
-		Datastore: request.Datastore,
-		SQL:       SQL,
-	})
+// Migrate runs pending versioned migration scripts and records them in tracking table
+func (s *service) Migrate(request *MigrateRequest) *MigrateResponse {
+	var response = &MigrateResponse{
+		BaseResponse: NewBaseOkResponse(),
+	}
+	err := request.Init()
+	if err == nil {
+		err = request.Validate()
+	}
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	manager := s.registry.Get(request.Datastore)
+	response.SetError(s.migrate(request, manager, response))
+	return response
 }
 
 func (s *service) AddTableMapping(request *MappingRequest) *MappingResponse {
@@ -294,7 +304,18 @@ func (s *service) Init(request *InitRequest) *InitResponse {
 		}
 	}
 
-	if request.RunScriptRequest != nil && len(request.Scripts) > 0 {
+	if request.MigrationsURL != "" {
+		migrateRequest := NewMigrateRequest(request.Datastore, request.MigrationsURL)
+		if request.RunScriptRequest != nil {
+			migrateRequest.Expand = request.RunScriptRequest.Expand
+			migrateRequest.Transactional = request.RunScriptRequest.Transactional
+		}
+		serviceResponse := s.Migrate(migrateRequest)
+		if serviceResponse.Status != StatusOk {
+			response.BaseResponse = serviceResponse.BaseResponse
+			return response
+		}
+	} else if request.RunScriptRequest != nil && len(request.Scripts) > 0 {
 		if request.RunScriptRequest.Datastore == "" {
 			request.RunScriptRequest.Datastore = request.Datastore
 		}
@@ -459,6 +480,11 @@ func (s *service) prepare(request *PrepareRequest, response *PrepareResponse, ma
 		threads = 1
 	}
 	ctx := s.newContext(manager)
+	bases := newSequenceBases()
+	s.sequenceMux.Lock()
+	s.sequenceBases[request.Datastore] = bases
+	s.sequenceMux.Unlock()
+	_ = ctx.Replace((*sequenceBases)(nil), bases)
 
 	var pending = make(chan bool, threads)
 	wg := sync.WaitGroup{}
@@ -538,7 +564,17 @@ func (s *service) prepareWithRequest(request *PrepareRequest, response *PrepareR
 		return err
 	}
 	s.prepare(request, response, manager, connection)
-	return s.enableForeignKeyCheck(request.Datastore, adminConnection)
+	if err = s.enableForeignKeyCheck(request.Datastore, adminConnection); err != nil || !request.SyncSequences || response.Status != StatusOk {
+		return err
+	}
+	var tables = make([]string, 0, len(response.Modification))
+	for table, modification := range response.Modification {
+		if modification.Added+modification.Modified > 0 {
+			tables = append(tables, table)
+		}
+	}
+	response.Sequences, response.SkippedSequences, err = s.syncSequences(manager, tables, request.Sequences)
+	return err
 }
 
 func (s *service) enableForeignKeyCheck(datastore string, connection dsc.Connection) error {
@@ -584,11 +620,11 @@ func (s *service) disableForeignKeyCheck(datastore string, connection dsc.Connec
 	return connection, err
 }
 
-func (s *service) expect(policy int, dataset *Dataset, response *ExpectResponse, context toolbox.Context, manager dsc.Manager) (err error) {
+func (s *service) expect(request *ExpectRequest, dataset *Dataset, response *ExpectResponse, context toolbox.Context, manager dsc.Manager) (err error) {
 	if s.mapper.Has(dataset.Table) {
 		datasets := s.mapper.Map(dataset)
 		for _, dataset := range datasets {
-			if err = s.expect(policy, dataset, response, context, manager); err != nil {
+			if err = s.expect(request, dataset, response, context, manager); err != nil {
 				return err
 			}
 		}
@@ -627,7 +663,7 @@ func (s *service) expect(policy int, dataset *Dataset, response *ExpectResponse,
 	var validation = &DatasetValidation{
 		Dataset: dataset.Table,
 	}
-
+	var policy = request.CheckPolicy
 	if policy == FullTableDatasetCheckPolicy || len(table.PkColumns) == 0 { //no keys perform insert
 
 		parametrizedSQL = sqlBuilder.BuildQueryAll(columns)
@@ -662,6 +698,17 @@ func (s *service) expect(policy int, dataset *Dataset, response *ExpectResponse,
 		}
 	}
 
+	if len(request.Encrypted) > 0 {
+		if request.EncryptExpected {
+			err = encryptRecords(request.Encrypted, expectedRecords)
+		} else {
+			err = decryptRecords(request.Encrypted, actual)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
 	validation.Expected = expectedRecords
 	validation.Actual = actual
 	validation.Validation, err = assertly.Assert(expectedRecords, actual, assertly.NewDataPath(table.Table))
@@ -705,6 +752,12 @@ func (s *service) Expect(request *ExpectRequest) *ExpectResponse {
 	}
 	manager := s.registry.Get(request.Datastore)
 	ctx := s.newContext(manager)
+	s.sequenceMux.RLock()
+	bases, ok := s.sequenceBases[request.Datastore]
+	s.sequenceMux.RUnlock()
+	if ok {
+		_ = ctx.Replace((*sequenceBases)(nil), bases)
+	}
 
 	err = request.Load()
 	response.SetError(err)
@@ -713,8 +766,11 @@ func (s *service) Expect(request *ExpectRequest) *ExpectResponse {
 			response.SetError(fmt.Errorf("no dataset: %v/%v", request.URL, request.Prefix+"*"+request.Postfix))
 			return response
 		}
+		for i := range request.Encrypted {
+			request.Encrypted[i].Init(context.Background())
+		}
 		for _, dataset := range request.Datasets {
-			if err = s.expect(request.CheckPolicy, dataset, response, ctx, manager); err != nil {
+			if err = s.expect(request, dataset, response, ctx, manager); err != nil {
 				break
 			}
 		}
@@ -731,6 +787,14 @@ func (s *service) Query(request *QueryRequest) *QueryResponse {
 		Records:      make([]map[string]interface{}, 0),
 		Validation:   &assertly.Validation{},
 	}
+	err := request.Init()
+	if err == nil {
+		err = request.Validate()
+	}
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
 	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
 		return response
 	}
@@ -746,14 +810,22 @@ func (s *service) Query(request *QueryRequest) *QueryResponse {
 	if state != nil {
 		SQL = state.Expand(toolbox.AsString(SQL))
 	}
-	err = manager.ReadAll(&response.Records, toolbox.AsString(SQL), nil, nil)
+	query, params, err := request.queryParameters(toolbox.AsString(SQL))
 	if err != nil {
 		response.SetError(err)
 		return response
 	}
-	if len(request.Expect) > 0 {
-		response.Validation, err = assertly.Assert(request.Expect, response.Records, assertly.NewDataPath("sql"))
+	if request.DestURL != "" {
+		response.SetError(s.exportQuery(manager, request, query, params, response))
+		return response
+	}
+	err = manager.ReadAll(&response.Records, query, params, nil)
+	if err != nil {
 		response.SetError(err)
+		return response
+	}
+	if request.HasExpect() {
+		response.SetError(s.expectQuery(request, manager, response))
 	}
 	return response
 }
@@ -783,81 +855,49 @@ func (s *service) Freeze(request *FreezeRequest) *FreezeResponse {
 		return response
 	}
 
-	var locationTimezone *time.Location
-	if request.LocationTimezone != "" {
-		if locationTimezone, err = time.LoadLocation(request.LocationTimezone); err != nil {
-			response.SetError(err)
-			return response
-		}
-	}
-	relativeDates := map[string]bool{}
-	if len(request.RelativeDate) > 0 {
-		for _, item := range request.RelativeDate {
-			relativeDates[item] = true
-		}
+	transformer, err := newRecordTransformer(request)
+	if err != nil {
+		response.SetError(err)
+		return response
 	}
 
-	if len(request.Obfuscation) > 0 {
-		for i := range request.Obfuscation {
-			item := request.Obfuscation[i]
-			item.Init(context.Background())
+	var volatile map[string]string
+	if request.ExpectMode {
+		volatile = detectVolatileColumns(records, request)
+		if table := request.TableName(); table != "" {
+			dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+			datastore, _ := dialect.GetCurrentDatastore(manager)
+			if pk := dialect.GetKeyName(manager, datastore, table); pk != "" {
+				for _, column := range strings.Split(pk, ",") {
+					delete(volatile, column)
+				}
+			}
 		}
 	}
 
 	destResource := dsurl.NewResource(request.DestURL)
-	if len(records) > 0 {
-
-		for i := range records {
-
-			if request.OmitEmpty {
-				records[i] = toolbox.DeleteEmptyKeys(records[i])
-			}
-			adjustTime(locationTimezone, request, records[i], relativeDates)
-			if err = obfuscateData(context.Background(), records[i], request.Obfuscation); err != nil {
-				response.SetError(err)
-				return response
-			}
-
-			if len(request.Override) > 0 {
-				for k, v := range request.Override {
-					if _, has := records[i][k]; has {
-						records[i][k] = v
-					}
-				}
-			}
-			if len(request.Ignore) > 0 {
-				var record = data.Map(records[i])
-				for _, path := range request.Ignore {
-					record.Delete(path)
-				}
-				records[i] = record
-			}
-			if len(request.Replace) > 0 {
-				var record = data.Map(records[i])
-				for k, v := range request.Replace {
-					record.Replace(k, escapeVariableIfNeeded(v))
-				}
-				records[i] = record
-			}
+	for i := range records {
+		if records[i], err = transformer.transform(records[i]); err != nil {
+			response.SetError(err)
+			return response
+		}
+	}
 
-			if len(request.ASCII) > 0 {
-				for _, column := range request.ASCII {
-					if val, ok := records[i][column]; ok {
-						switch actual := val.(type) {
-						case string:
-							records[i][column] = strings.TrimFunc(actual, func(r rune) bool {
-								return !unicode.IsGraphic(r)
-							})
-						case []byte:
-							records[i][column] = strings.TrimFunc(string(actual), func(r rune) bool {
-								return !unicode.IsGraphic(r)
-							})
-						}
-					}
-				}
+	replaceVolatileValues(records, volatile)
 
-			}
+	var directive map[string]interface{}
+	if request.KeyDirective || request.SymbolicIDs || len(request.References) > 0 {
+		if directive, err = s.freezeKeys(manager, request, records); err != nil {
+			response.SetError(err)
+			return response
+		}
+	}
 
+	if request.PII != nil {
+		response.PII = scanPII(records, request.maskedColumns())
+		if request.PII.Strict && response.PII.HasPII() {
+			response.SetError(fmt.Errorf("detected unmasked PII: %v", response.PII.Summary()))
+			return response
 		}
 	}
 
@@ -866,6 +906,9 @@ func (s *service) Freeze(request *FreezeRequest) *FreezeResponse {
 			map[string]interface{}{},
 		}, records...)
 	}
+	if len(directive) > 0 {
+		records = append([]map[string]interface{}{directive}, records...)
+	}
 	payload, err := toolbox.AsIndentJSONText(records)
 	if err != nil {
 		response.SetError(err)
@@ -877,6 +920,68 @@ func (s *service) Freeze(request *FreezeRequest) *FreezeResponse {
 	return response
 }
 
+// freezeKeys returns table key directive, optionally rewrites autoincrement ids and referencing columns into <ds:seq["table", offset]> expressions,
+// where offset is relative to the referenced table min key value, so independently frozen datasets share the same offsets
+func (s *service) freezeKeys(manager dsc.Manager, request *FreezeRequest, records []map[string]interface{}) (map[string]interface{}, error) {
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, err := dialect.GetCurrentDatastore(manager)
+	if err != nil {
+		return nil, err
+	}
+	var directive map[string]interface{}
+	table := request.TableName()
+	if table != "" {
+		pk := dialect.GetKeyName(manager, datastore, table)
+		autoincrement := pk != "" && dialect.IsAutoincrement(manager, datastore, table)
+		if request.KeyDirective && pk != "" {
+			if autoincrement {
+				directive = map[string]interface{}{AutoincrementDirective: pk}
+			} else {
+				directive = map[string]interface{}{assertly.IndexByDirective: strings.Split(pk, ",")}
+			}
+		}
+		if request.SymbolicIDs && autoincrement && !strings.Contains(pk, ",") {
+			if err = s.rewriteSymbolicIDs(manager, records, pk, table, pk); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for column, refTable := range request.References {
+		refKey := dialect.GetKeyName(manager, datastore, refTable)
+		if refKey == "" || strings.Contains(refKey, ",") {
+			return nil, fmt.Errorf("unable to reference %v.%v: unsupported key: '%v'", refTable, column, refKey)
+		}
+		if err = s.rewriteSymbolicIDs(manager, records, column, refTable, refKey); err != nil {
+			return nil, err
+		}
+	}
+	return directive, nil
+}
+
+func (s *service) rewriteSymbolicIDs(manager dsc.Manager, records []map[string]interface{}, column, table, key string) error {
+	var row = make([]interface{}, 0)
+	SQL := fmt.Sprintf("SELECT MIN(%v) FROM %v", key, table)
+	if _, err := manager.ReadSingle(&row, SQL, nil, nil); err != nil {
+		return fmt.Errorf("failed to lookup %v min key: %v", table, err)
+	}
+	if len(row) == 0 || row[0] == nil {
+		return nil
+	}
+	anchor := toolbox.AsInt(row[0])
+	for _, record := range records {
+		value, ok := record[column]
+		if !ok || value == nil {
+			continue
+		}
+		id, err := toolbox.ToInt(value)
+		if err != nil {
+			continue
+		}
+		record[column] = fmt.Sprintf(`<ds:seq["%v", %v]>`, table, id-anchor)
+	}
+	return nil
+}
+
 func obfuscateData(ctx context.Context, m map[string]interface{}, obfuscation []Obfuscation) error {
 	if len(obfuscation) == 0 {
 		return nil
@@ -898,6 +1003,76 @@ func obfuscateData(ctx context.Context, m map[string]interface{}, obfuscation []
 	return err
 }
 
+// encryptRecords encrypts expected records columns, dsunit macros, regexp predicates and directives are left intact
+func encryptRecords(rules []Obfuscation, records []interface{}) error {
+	ctx := context.Background()
+	for _, item := range records {
+		record := asRecordMap(item)
+		if record == nil {
+			continue
+		}
+		for i := range rules {
+			rule := &rules[i]
+			for _, column := range rule.Columns {
+				value, ok := record[column].(string)
+				if !ok || isExpressionValue(value) {
+					continue
+				}
+				encrypted, err := rule.Obfuscate(ctx, value, record, column)
+				if err != nil {
+					return fmt.Errorf("failed to encrypt %v: %v", column, err)
+				}
+				record[column] = encrypted
+			}
+		}
+	}
+	return nil
+}
+
+// decryptRecords decrypts actual records columns
+func decryptRecords(rules []Obfuscation, records []interface{}) error {
+	ctx := context.Background()
+	for _, item := range records {
+		record := asRecordMap(item)
+		if record == nil {
+			continue
+		}
+		for i := range rules {
+			rule := &rules[i]
+			for _, column := range rule.Columns {
+				value, ok := record[column]
+				if !ok || value == nil {
+					continue
+				}
+				decrypted, err := rule.Decrypt(ctx, toolbox.AsString(value), column)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt %v: %v", column, err)
+				}
+				record[column] = decrypted
+			}
+		}
+	}
+	return nil
+}
+
+func asRecordMap(item interface{}) map[string]interface{} {
+	switch actual := item.(type) {
+	case map[string]interface{}:
+		return actual
+	case *map[string]interface{}:
+		if actual != nil {
+			return *actual
+		}
+	}
+	return nil
+}
+
+// isExpressionValue returns true if value is dsunit macro, assertly regexp predicate or directive, other values are plain data
+func isExpressionValue(value string) bool {
+	return strings.HasPrefix(value, "<ds:") || strings.HasPrefix(value, "~/") ||
+		len(value) > 1 && strings.HasPrefix(value, "@") && strings.HasSuffix(value, "@")
+}
+
 func adjustTime(locationTimezone *time.Location, request *FreezeRequest, record map[string]interface{}, relativeDates map[string]bool) {
 	if locationTimezone != nil || request.TimeLayout != "" {
 		for k, v := range record {
@@ -931,10 +1106,128 @@ func adjustTime(locationTimezone *time.Location, request *FreezeRequest, record
 	}
 }
 
+// recordTransformer applies freeze record transformations (timezone, obfuscation, overrides, ignore, replace, ascii)
+type recordTransformer struct {
+	request          *FreezeRequest
+	locationTimezone *time.Location
+	relativeDates    map[string]bool
+}
+
+func newRecordTransformer(request *FreezeRequest) (*recordTransformer, error) {
+	var err error
+	result := &recordTransformer{request: request, relativeDates: make(map[string]bool)}
+	if request.LocationTimezone != "" {
+		if result.locationTimezone, err = time.LoadLocation(request.LocationTimezone); err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range request.RelativeDate {
+		result.relativeDates[item] = true
+	}
+	for i := range request.Obfuscation {
+		request.Obfuscation[i].Init(context.Background())
+	}
+	return result, nil
+}
+
+// transform returns transformed record
+func (t *recordTransformer) transform(record map[string]interface{}) (map[string]interface{}, error) {
+	request := t.request
+	if request.OmitEmpty {
+		record = toolbox.DeleteEmptyKeys(record)
+	}
+	adjustTime(t.locationTimezone, request, record, t.relativeDates)
+	if err := obfuscateData(context.Background(), record, request.Obfuscation); err != nil {
+		return nil, err
+	}
+	for k, v := range request.Override {
+		if _, has := record[k]; has {
+			record[k] = v
+		}
+	}
+	if len(request.Ignore) > 0 {
+		var aMap = data.Map(record)
+		for _, path := range request.Ignore {
+			aMap.Delete(path)
+		}
+		record = aMap
+	}
+	if len(request.Replace) > 0 {
+		var aMap = data.Map(record)
+		for k, v := range request.Replace {
+			aMap.Replace(k, escapeVariableIfNeeded(v))
+		}
+		record = aMap
+	}
+	for _, column := range request.ASCII {
+		if val, ok := record[column]; ok {
+			switch actual := val.(type) {
+			case string:
+				record[column] = strings.TrimFunc(actual, func(r rune) bool {
+					return !unicode.IsGraphic(r)
+				})
+			case []byte:
+				record[column] = strings.TrimFunc(string(actual), func(r rune) bool {
+					return !unicode.IsGraphic(r)
+				})
+			}
+		}
+	}
+	return record, nil
+}
+
+// Scaffold creates prepare, expect datasets and go test skeleton from existing database
+func (s *service) Scaffold(request *ScaffoldRequest) *ScaffoldResponse {
+	var response = &ScaffoldResponse{BaseResponse: NewBaseOkResponse(), Datasets: make([]string, 0)}
+	if err := request.Init(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if err := request.Validate(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	if err := s.scaffold(request, response); err != nil {
+		response.SetError(err)
+	}
+	return response
+}
+
+// Clone copies tables schema and data from source to dest datastore, including across vendors
+func (s *service) Clone(request *CloneRequest) *CloneResponse {
+	var response = &CloneResponse{BaseResponse: NewBaseOkResponse(), DDLs: make([]string, 0), Counts: make(map[string]int)}
+	if err := request.Init(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if err := request.Validate(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore, request.DestDatastore) {
+		return response
+	}
+	if err := s.clone(request, response); err != nil {
+		response.SetError(err)
+	}
+	return response
+}
+
 //Dump creates a database schema from existing database
 
 func (s *service) Dump(request *DumpRequest) *DumpResponse {
 	var response = &DumpResponse{BaseResponse: NewBaseOkResponse()}
+	if err := request.Init(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if err := request.Validate(); err != nil {
+		response.SetError(err)
+		return response
+	}
 	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
 		return response
 	}
@@ -953,40 +1246,111 @@ func (s *service) getTableNames(manager dsc.Manager, datastore string) ([]string
 	return dialect.GetTables(manager, dbNme)
 }
 
+// dump writes tables DDL and data ordered so that referenced tables go before referencing ones
 func (s *service) dump(request *DumpRequest, response *DumpResponse) error {
 	var err error
 	manager := s.registry.Get(request.Datastore)
 	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, err := dialect.GetCurrentDatastore(manager)
+	if err != nil {
+		return err
+	}
 	tables := request.Tables
 	if len(tables) == 0 {
 		if tables, err = s.getTableNames(manager, request.Datastore); err != nil {
 			return err
 		}
 	}
-
-	destResource := dsurl.NewResource(request.DestURL)
-	var DDLs = []string{}
+	views, err := s.readViews(manager)
+	if err != nil {
+		return err
+	}
+	sequences, err := s.readSequences(manager)
+	if err != nil {
+		return err
+	}
+	if len(request.Tables) > 0 {
+		views = dumpedViews(views, tables)
+	}
+	tables = excludeViews(tables, views)
 
 	hasTarget := request.Target != ""
 	if manager.Config().DriverName == request.Target {
 		hasTarget = false
 	}
-
+	target, mappingURL := "", ""
+	if hasTarget {
+		target, mappingURL = request.Target, request.MappingURL
+	}
+	var schemas = make([]*TableSchema, 0, len(tables))
 	for _, table := range tables {
+		tableSchema, err := s.tableSchema(manager, table, mappingURL, target)
+		if err != nil {
+			return err
+		}
+		schemas = append(schemas, tableSchema)
+	}
+	schemas = orderByForeignKeys(schemas)
+	tables = make([]string, 0, len(schemas))
+	for _, tableSchema := range schemas {
+		tables = append(tables, tableSchema.Table)
+	}
+
+	destResource := dsurl.NewResource(request.DestURL)
+	var DDLs = []string{}
+
+	if request.Mode != DumpModeData {
 		if hasTarget {
-			targetDDL, err := s.getCreateTableDDL(manager, request, table)
-			if err != nil {
-				return err
+			var defaults = make([]string, 0)
+			for _, tableSchema := range schemas {
+				for _, value := range tableSchema.Defaults {
+					defaults = append(defaults, value)
+				}
+			}
+			if len(request.Tables) > 0 {
+				sequences = referencedSequences(sequences, defaults)
+			}
+			ddlDialect := getDDLDialect(request.Target)
+			if ddlDialect != getDDLDialect(manager.Config().DriverName) && len(views) > 0 {
+				for _, view := range views {
+					response.Warnings = append(response.Warnings, fmt.Sprintf("skipped view %v: %v SQL was not translated to %v", view.Name, manager.Config().DriverName, request.Target))
+				}
+				views = nil
+			}
+			DDLs = ddlDialect.schemaDDLs(ddlDialect.schemaName(datastore), sequences, schemas, views)
+		} else {
+			ddlDialect := getDDLDialect(manager.Config().DriverName)
+			var tableDDLs = make([]string, 0, len(tables))
+			for _, table := range tables {
+				ddl, err := dialect.ShowCreateTable(manager, table)
+				if err != nil {
+					return err
+				}
+				if !strings.HasSuffix(strings.TrimSpace(ddl), ";") {
+					ddl = strings.TrimSpace(ddl) + ";"
+				}
+				tableDDLs = append(tableDDLs, ddl)
+			}
+			if len(request.Tables) > 0 {
+				sequences = referencedSequences(sequences, tableDDLs)
+			}
+			if ddlDialect.sequences {
+				for _, sequence := range sequences {
+					DDLs = append(DDLs, ddlDialect.createSequence(sequence))
+				}
+			}
+			DDLs = append(DDLs, tableDDLs...)
+			for _, view := range views {
+				DDLs = append(DDLs, ddlDialect.createView(datastore, view))
 			}
-			DDLs = append(DDLs, targetDDL)
-			continue
 		}
-
-		ddl, err := dialect.ShowCreateTable(manager, table)
+	}
+	if request.Mode != DumpModeSchema {
+		inserts, err := s.dumpData(manager, request, tables)
 		if err != nil {
 			return err
 		}
-		DDLs = append(DDLs, ddl)
+		DDLs = append(DDLs, inserts...)
 	}
 
 	var payload = strings.Join(DDLs, "\n\n")
@@ -996,29 +1360,19 @@ func (s *service) dump(request *DumpRequest, response *DumpResponse) error {
 	return nil
 }
 
-func (s *service) getOrLoadMapping(target, mappingURL string) (map[string]string, error) {
-	var result = make(map[string]string)
-	if target == "" {
-		return result, nil
-	}
-
-	if len(dbTypeMappings) == 0 {
-		loadDefaultDbMappings()
-	}
-	mapping, ok := dbTypeMappings[target]
-	if !ok && mappingURL == "" {
-		return nil, fmt.Errorf("unsupported target: %v, consider adding mapping URL", target)
+// excludeViews removes views from tables
+func excludeViews(tables []string, views []*View) []string {
+	var viewNames = make(map[string]bool)
+	for _, view := range views {
+		viewNames[strings.ToLower(view.Name)] = true
 	}
-	if mappingURL != "" {
-		location := url.Normalize(mappingURL, file.Scheme)
-		err := dsurl.Decode(location, mapping)
-		if err != nil {
-			return nil, err
+	var result = make([]string, 0, len(tables))
+	for _, table := range tables {
+		if !viewNames[strings.ToLower(table)] {
+			result = append(result, table)
 		}
-		dbTypeMappings[target] = mapping
 	}
-
-	return mapping, nil
+	return result
 }
 
 func (s *service) TableInfo(manager dsc.Manager, table, mappingURL, target string) (*dsc.TableDescriptor, error) {
@@ -1029,29 +1383,17 @@ func (s *service) TableInfo(manager dsc.Manager, table, mappingURL, target strin
 		return nil, err
 	}
 
-	mapping, err := s.getOrLoadMapping(target, mappingURL)
+	mapper, err := getTypeMapper(manager.Config().DriverName, target, mappingURL)
+	if err != nil {
+		return nil, err
+	}
 	result := &dsc.TableDescriptor{Table: table, ColumnTypes: make(map[string]string), Columns: make([]string, 0), Nullables: make(map[string]bool)}
 	pk := dialect.GetKeyName(manager, datastore, table)
 	result.PkColumns = strings.Split(pk, ",")
 
 	for _, column := range columns {
 		result.Columns = append(result.Columns, column.Name())
-		dbType := strings.ToUpper(column.DatabaseTypeName())
-
-		if dbType == "TINYINT" {
-			if size, ok := column.Length(); ok && size > 1 {
-				dbType = "SMALLINT"
-			}
-		}
-		if strings.HasSuffix(strings.ToLower(column.Name()), "id") && dbType == "NUMERIC" {
-			dbType = "INTEGER"
-		}
-
-		if mapedType, ok := mapping[dbType]; ok {
-			dbType = mapedType
-		}
-
-		result.ColumnTypes[column.Name()] = dbType
+		result.ColumnTypes[column.Name()] = mapper.Map(column.Name(), columnType(column))
 		if nullable, ok := column.Nullable(); ok {
 			result.Nullables[column.Name()] = nullable
 		}
@@ -1059,50 +1401,49 @@ func (s *service) TableInfo(manager dsc.Manager, table, mappingURL, target strin
 	return result, nil
 }
 
-func (s *service) getCreateTableDDL(manager dsc.Manager, request *DumpRequest, table string) (string, error) {
-	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
-	datastore, _ := dialect.GetCurrentDatastore(manager)
-	tableInfo, err := s.TableInfo(manager, table, request.MappingURL, request.Target)
-	if err != nil {
-		return "", err
-	}
-	var ddlColumns = []string{}
-	for _, columnName := range tableInfo.Columns {
-		var ddlColumn = "\t" + columnName
-		dbType := tableInfo.ColumnTypes[columnName]
-
-		ddlColumn += " " + dbType
-		if nullable, ok := tableInfo.Nullables[columnName]; ok && !nullable {
-			ddlColumn += " NOT NULL"
-		}
-		ddlColumns = append(ddlColumns, ddlColumn)
-	}
-	return fmt.Sprintf("CREATE TABLE %v.%v(\n%v);\n", strings.ToLower(datastore), table, strings.Join(ddlColumns, ",\n")), nil
-}
-
-// Sequence returns sequence for supplied tables
+// Ping waits until datastores are online and satisfy readiness conditions or timeout
 func (s *service) Ping(request *PingRequest) *PingResponse {
 	response := &PingResponse{
 		BaseResponse: NewBaseOkResponse(),
 	}
+	err := request.Init()
+	if err == nil {
+		err = request.Validate()
+	}
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
 	timeout := 30 * time.Second
 	if request.TimeoutMs > 0 {
 		timeout = time.Duration(request.TimeoutMs) * time.Millisecond
 	}
-	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
-		return response
+	var readiness = make(map[string]*Readiness)
+	for datastore, conditions := range request.Datastores {
+		readiness[datastore] = conditions
 	}
-	manager := s.registry.Get(request.Datastore)
-	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
-	startTime := time.Now()
-	var err error
-	for time.Now().Sub(startTime) <= timeout {
-		if err = dialect.Ping(manager); err == nil {
-			break
+	if request.Datastore != "" {
+		readiness[request.Datastore] = request.Readiness
+	}
+	for datastore := range readiness {
+		if !validateDatastores(s.registry, response.BaseResponse, datastore) {
+			return response
 		}
-		time.Sleep(5 * time.Second)
 	}
-	response.SetError(err)
+	response.NotReady = s.pingDatastores(readiness, request.Backoff, timeout)
+	if len(response.NotReady) == 0 {
+		return response
+	}
+	var datastores = make([]string, 0, len(response.NotReady))
+	for datastore := range response.NotReady {
+		datastores = append(datastores, datastore)
+	}
+	sort.Strings(datastores)
+	var messages = make([]string, 0, len(datastores))
+	for _, datastore := range datastores {
+		messages = append(messages, datastore+": "+response.NotReady[datastore])
+	}
+	response.SetError(fmt.Errorf("datastores were not ready: %v", strings.Join(messages, "; ")))
 	return response
 }
 
@@ -1129,6 +1470,25 @@ func (s *service) Sequence(request *SequenceRequest) *SequenceResponse {
 	return response
 }
 
+// SyncSequences sets supplied tables sequence or identity next value to max(pk)+1
+func (s *service) SyncSequences(request *SyncSequencesRequest) *SyncSequencesResponse {
+	var response = &SyncSequencesResponse{
+		BaseResponse: NewBaseOkResponse(),
+	}
+	if err := request.Validate(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	manager := s.registry.Get(request.Datastore)
+	var err error
+	response.Sequences, response.Skipped, err = s.syncSequences(manager, request.Tables, request.Sequences)
+	response.SetError(err)
+	return response
+}
+
 func (s *service) SetContext(context toolbox.Context) {
 	s.context = context
 }
@@ -1169,6 +1529,10 @@ func (s *service) Compare(request *CompareRequest) *CompareResponse {
 		BaseResponse: NewBaseOkResponse(),
 		Validation:   &assertly.Validation{},
 	}
+	if err := request.Validate(); err != nil {
+		response.SetError(err)
+		return response
+	}
 
 	if !validateDatastores(s.registry, response.BaseResponse, request.Source1.Datastore) {
 		return response
@@ -1183,11 +1547,41 @@ func (s *service) Compare(request *CompareRequest) *CompareResponse {
 	if len(request.Directives) == 0 {
 		request.Directives = make(map[string]interface{})
 	}
-	s.compare(manager1, manager2, request, response)
+	report, err := newDiscrepancyReport(request)
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	switch {
+	case request.Checksum != nil:
+		s.checksumCompare(manager1, manager2, request, response, report)
+	case request.Stream:
+		s.streamCompare(manager1, manager2, request, response, report)
+	default:
+		s.compare(manager1, manager2, request, response, report)
+	}
+	if err = report.Close(); err != nil {
+		response.SetError(err)
+	}
 	return response
 }
 
-func (s *service) compare(manager1 dsc.Manager, manager2 dsc.Manager, request *CompareRequest, response *CompareResponse) {
+// CompareBatch compares many named SQL pairs concurrently and summarizes results
+func (s *service) CompareBatch(request *CompareBatchRequest) *CompareBatchResponse {
+	var response = &CompareBatchResponse{BaseResponse: NewBaseOkResponse()}
+	if err := request.Init(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if err := request.Validate(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	s.compareBatch(request, response)
+	return response
+}
+
+func (s *service) compare(manager1 dsc.Manager, manager2 dsc.Manager, request *CompareRequest, response *CompareResponse, report *discrepancyReport) {
 	var err error
 	data1 := data.NewCompactedSlice(request.OmitEmpty, true)
 	data2 := data.NewCompactedSlice(request.OmitEmpty, true)
@@ -1196,14 +1590,14 @@ func (s *service) compare(manager1 dsc.Manager, manager2 dsc.Manager, request *C
 	waitGroup.Add(2)
 	go func() {
 		defer waitGroup.Done()
-		if e := manager1.ReadAllWithHandler(request.Source1.SQL, nil, compactedSliceReader(data1, request.Directives)); e != nil {
+		if e := manager1.ReadAllWithHandler(request.Source1.SQL, nil, compactedSliceReader(data1, request, true)); e != nil {
 			err = e
 		}
 		response.Dataset1Count = data1.Size()
 	}()
 	go func() {
 		defer waitGroup.Done()
-		if e := manager2.ReadAllWithHandler(request.Source2.SQL, nil, compactedSliceReader(data2, request.Directives)); e != nil {
+		if e := manager2.ReadAllWithHandler(request.Source2.SQL, nil, compactedSliceReader(data2, request, false)); e != nil {
 			err = e
 		}
 		response.Dataset2Count = data2.Size()
@@ -1214,6 +1608,27 @@ func (s *service) compare(manager1 dsc.Manager, manager2 dsc.Manager, request *C
 		return
 	}
 
+	if report != nil && (response.Dataset1Count > 0 || response.Dataset2Count > 0) {
+		indexBy := request.IndexBy()
+		sorted1, err := newSliceIterator(data1.Iterator(), indexBy)
+		if err != nil {
+			response.SetError(err)
+			return
+		}
+		sorted2, err := newSliceIterator(data2.Iterator(), indexBy)
+		if err != nil {
+			response.SetError(err)
+			return
+		}
+		if err = s.mergeCompare(sorted1, sorted2, request, response, indexBy, report); err != nil {
+			response.SetError(err)
+		}
+		return
+	}
+	if response.MissingRows, response.ExtraRows, err = unmatchedRows(data1.Iterator(), data2.Iterator(), request.IndexBy()); err != nil {
+		response.SetError(err)
+		return
+	}
 	if response.Dataset1Count == 0 {
 		if response.Dataset2Count == 0 {
 			response.AddFailure(assertly.NewFailure("", "", "no data", response.Dataset1Count, response.Dataset2Count))
@@ -1306,6 +1721,7 @@ func (s *service) compare(manager1 dsc.Manager, manager2 dsc.Manager, request *C
 		response.PassedCount += validation.PassedCount
 		if validation.HasFailure() {
 			discrepantRowCount++
+			response.MismatchedRows++
 			for _, failure := range validation.Failures {
 				response.AddFailure(failure)
 			}
@@ -1349,11 +1765,22 @@ func removeIgnoredColumns(request *CompareRequest, record1, record2 map[string]i
 	}
 }
 
-func compactedSliceReader(aSlice *data.CompactedSlice, directives map[string]interface{}) func(scanner dsc.Scanner) (toContinue bool, err error) {
+func compactedSliceReader(aSlice *data.CompactedSlice, request *CompareRequest, source1 bool) func(scanner dsc.Scanner) (toContinue bool, err error) {
+	read := recordReader(request, source1)
+	return func(scanner dsc.Scanner) (toContinue bool, err error) {
+		record, err := read(scanner)
+		if err == nil {
+			aSlice.Add(record)
+		}
+		return err == nil, err
+	}
+}
 
+// recordReader returns scanner record reader renaming source1 columns with column mapping, formatting time columns with @timeFormat@ directives and applying column coercions
+func recordReader(request *CompareRequest, source1 bool) func(scanner dsc.Scanner) (map[string]interface{}, error) {
 	var timeDirectives = make(map[string]string)
-	if len(directives) > 0 {
-		for k, v := range directives {
+	if len(request.Directives) > 0 {
+		for k, v := range request.Directives {
 			if strings.HasPrefix(k, assertly.TimeFormatDirective) {
 				column := string(k[len(assertly.TimeFormatDirective):])
 				if column == "" {
@@ -1363,25 +1790,34 @@ func compactedSliceReader(aSlice *data.CompactedSlice, directives map[string]int
 			}
 		}
 	}
-	return func(scanner dsc.Scanner) (toContinue bool, err error) {
+	return func(scanner dsc.Scanner) (map[string]interface{}, error) {
 		record := make(map[string]interface{})
-		if err = scanner.Scan(record); err == nil {
-			for k, timeLayout := range timeDirectives {
-				if val, ok := record[k]; ok {
-					timeVal, err := toolbox.ToTime(val, timeLayout)
-					if err == nil {
-						record[k] = timeVal.Format(timeLayout)
-					}
+		if err := scanner.Scan(record); err != nil {
+			return nil, err
+		}
+		if source1 && len(request.ColumnMapping) > 0 {
+			record = mapColumns(record, request.ColumnMapping)
+		}
+		for k, timeLayout := range timeDirectives {
+			if val, ok := record[k]; ok {
+				timeVal, err := toolbox.ToTime(val, timeLayout)
+				if err == nil {
+					record[k] = timeVal.Format(timeLayout)
 				}
 			}
-			aSlice.Add(record)
 		}
-		return err == nil, err
+		if len(request.Coercions) > 0 {
+			coerceColumns(record, request.Coercions)
+		}
+		return record, nil
 	}
 }
 
-func (s *service) getTables(schema *SchemaTarget, tables []string) (map[string]*dsc.TableDescriptor, error) {
+func (s *service) getTables(schema *SchemaTarget, tables []string) (map[string]*TableSchema, error) {
 	var err error
+	if schema.URL != "" {
+		return s.getScriptTables(schema, tables)
+	}
 	manager := s.registry.Get(schema.Datastore)
 	if manager == nil {
 		return nil, fmt.Errorf("failed to lookup manager for %s", schema.Datastore)
@@ -1391,10 +1827,10 @@ func (s *service) getTables(schema *SchemaTarget, tables []string) (map[string]*
 			return nil, err
 		}
 	}
-	var result = make(map[string]*dsc.TableDescriptor)
+	var result = make(map[string]*TableSchema)
 	for i := range tables {
 		tableName := tables[i]
-		table, err := s.TableInfo(manager, tableName, schema.MappingURL, schema.Target)
+		table, err := s.tableSchema(manager, tableName, schema.MappingURL, schema.Target)
 		if err != nil {
 			return nil, err
 		}
@@ -1403,6 +1839,38 @@ func (s *service) getTables(schema *SchemaTarget, tables []string) (map[string]*
 	return result, nil
 }
 
+// getScriptTables returns tables defined by DDL script
+func (s *service) getScriptTables(schema *SchemaTarget, tables []string) (map[string]*TableSchema, error) {
+	vendor := schema.Vendor
+	if vendor == "" && schema.Datastore != "" {
+		if manager := s.registry.Get(schema.Datastore); manager != nil {
+			vendor = manager.Config().DriverName
+		}
+	}
+	if vendor == "" {
+		vendor = schema.Target
+	}
+	mapper, err := getTypeMapper(vendor, schema.Target, schema.MappingURL)
+	if err != nil {
+		return nil, err
+	}
+	data, err := afs.New().DownloadWithURL(context.Background(), url.Normalize(schema.URL, file.Scheme))
+	if err != nil {
+		return nil, err
+	}
+	result, err := parseDDLSchema(string(data), script.DialectForDriver(vendor), mapper)
+	if err != nil || len(tables) == 0 {
+		return result, err
+	}
+	var filtered = make(map[string]*TableSchema)
+	for _, table := range tables {
+		if tableSchema, ok := result[table]; ok {
+			filtered[table] = tableSchema
+		}
+	}
+	return filtered, nil
+}
+
 func (s *service) CheckSchema(request *CheckSchemaRequest) *CheckSchemaResponse {
 	response := NewCheckSchemaResponse()
 	err := s.checkSchema(request, response)
@@ -1414,6 +1882,12 @@ func (s *service) CheckSchema(request *CheckSchemaRequest) *CheckSchemaResponse
 
 // CheckSchema checks schema
 func (s *service) checkSchema(request *CheckSchemaRequest, response *CheckSchemaResponse) error {
+	if err := request.Init(); err != nil {
+		return err
+	}
+	if err := request.Validate(); err != nil {
+		return err
+	}
 	source, err := s.getTables(request.Source, request.Tables)
 	if err != nil {
 		return err
@@ -1422,41 +1896,94 @@ func (s *service) checkSchema(request *CheckSchemaRequest, response *CheckSchema
 	if err != nil {
 		return err
 	}
-
-	for table, sourceTable := range source {
+	for _, table := range sortedTableNames(source) {
+		if request.Rules.IgnoreTable(table) {
+			delete(source, table)
+			continue
+		}
 		destTable, ok := dest[table]
 		if !ok {
-			response.Validation.AddFailure(assertly.NewFailure("", table, "missing table in dest", table, ""))
+			addMissingTable(request, response, table, SchemaDiffMissingTable, "missing table in dest")
 			continue
 		}
-		tableCheck := &SchemaTableCheck{Table: table}
-		if tableCheck.Validation, err = assertly.Assert(sourceTable.ColumnTypes, destTable.ColumnTypes, assertly.NewDataPath(table)); err != nil {
-			return err
+		tableCheck := checkTableSchema(request, source[table], destTable)
+		response.Tables = append(response.Tables, tableCheck)
+		response.Errors += tableCheck.Errors
+		response.Warnings += tableCheck.Warnings
+	}
+	for _, table := range sortedTableNames(dest) {
+		if _, ok := source[table]; ok || request.Rules.IgnoreTable(table) {
+			continue
 		}
-		if request.CheckNullables {
-			if nullableValidation, err := assertly.Assert(sourceTable.Nullables, destTable.Nullables, assertly.NewDataPath(fmt.Sprintf("%v/NULLABLE", table))); err == nil {
-				tableCheck.PassedCount += nullableValidation.PassedCount
-				for j := range nullableValidation.Failures {
-					tableCheck.AddFailure(nullableValidation.Failures[j])
-				}
+		addMissingTable(request, response, table, SchemaDiffExtraTable, "missing table in source")
+	}
+	if request.DestURL != "" {
+		return s.migrateSchema(request, response, source, dest)
+	}
+	return nil
+}
+
+// addMissingTable reports table missing in source or dest, error differences are also reported as response validation failures
+func addMissingTable(request *CheckSchemaRequest, response *CheckSchemaResponse, table, kind, message string) {
+	tableCheck := &SchemaTableCheck{Table: table, Validation: assertly.NewValidation(), Differences: make([]*SchemaDifference, 0)}
+	tableCheck.addDifference(request.Rules, &SchemaDifference{Kind: kind, Message: message})
+	if tableCheck.Errors > 0 {
+		response.Validation.AddFailure(assertly.NewFailure("", table, message, table, ""))
+		tableCheck.Validation = assertly.NewValidation()
+	}
+	if len(tableCheck.Differences) == 0 {
+		return
+	}
+	response.Tables = append(response.Tables, tableCheck)
+	response.Errors += tableCheck.Errors
+	response.Warnings += tableCheck.Warnings
+}
+
+// migrateSchema writes DDL bringing dest schema in line with source
+func (s *service) migrateSchema(request *CheckSchemaRequest, response *CheckSchemaResponse, source, dest map[string]*TableSchema) error {
+	manager := s.registry.Get(request.Dest.Datastore)
+	datastore, err := dsc.GetDatastoreDialect(manager.Config().DriverName).GetCurrentDatastore(manager)
+	if err != nil {
+		return err
+	}
+	ddlDialect := getDDLDialect(manager.Config().DriverName)
+	schema := ddlDialect.schemaName(datastore)
+	var missing = make([]*TableSchema, 0)
+	var alters = make([]string, 0)
+	for _, table := range sortedTableNames(source) {
+		destTable, ok := dest[table]
+		if !ok {
+			missing = append(missing, source[table])
+			continue
+		}
+		for _, DDL := range ddlDialect.migrateTable(schema, source[table].TableDescriptor, destTable.TableDescriptor, request) {
+			if isUnsupportedDDL(DDL) {
+				response.Warnings++
 			}
+			alters = append(alters, DDL)
 		}
-		if request.CheckPrimaryKeys {
-			if pkValidation, err := assertly.Assert(sourceTable.PkColumns, destTable.PkColumns, assertly.NewDataPath(fmt.Sprintf("%v/PK", table))); err == nil {
-				tableCheck.PassedCount += pkValidation.PassedCount
-				for j := range pkValidation.Failures {
-					tableCheck.AddFailure(pkValidation.Failures[j])
-				}
+	}
+	response.Migration = append(ddlDialect.schemaDDLs(schema, nil, orderByForeignKeys(missing), nil), alters...)
+	if request.DropTables {
+		for _, table := range sortedTableNames(dest) {
+			if _, ok := source[table]; !ok && !request.Rules.IgnoreTable(table) {
+				response.Migration = append(response.Migration, ddlDialect.dropTable(schema, table))
 			}
 		}
-		response.Tables = append(response.Tables, tableCheck)
-		delete(dest, table)
 	}
+	destResource := dsurl.NewResource(request.DestURL)
+	response.DestURL = destResource.URL
+	uploadContent(destResource, response.BaseResponse, []byte(strings.Join(response.Migration, "\n\n")))
+	return nil
+}
 
-	for table := range dest {
-		response.Validation.AddFailure(assertly.NewFailure("", table, "missing table in source", table, ""))
+func sortedTableNames(tables map[string]*TableSchema) []string {
+	var result = make([]string, 0, len(tables))
+	for table := range tables {
+		result = append(result, table)
 	}
-	return nil
+	sort.Strings(result)
+	return result
 }
 
 // New creates new dsunit service
@@ -1465,6 +1992,7 @@ func New() Service {
 		registry:        dsc.NewManagerRegistry(),
 		mapper:          NewMapper(),
 		adminDatastores: make(map[string]string),
+		sequenceBases:   make(map[string]*sequenceBases),
 	}
 }
 
@@ -1,6 +1,7 @@
 package dsunit_test
 
 import (
+	"encoding/json"
 	"fmt"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/pkg/errors"
@@ -10,8 +11,11 @@ import (
 	"github.com/viant/dsunit"
 	"github.com/viant/dsunit/url"
 	"github.com/viant/toolbox"
+	"io/ioutil"
 	"log"
+	"os"
 	"path"
+	"strings"
 	"testing"
 )
 
@@ -62,6 +66,163 @@ func TestService_RunScript(t *testing.T) {
 	}
 }
 
+func TestService_RunSQLRecords(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	response := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	request := dsunit.NewRunSQLRequest("db1",
+		"DELETE FROM users WHERE id > 2",
+		"SELECT id, username FROM users ORDER BY id",
+		"SELECT COUNT(*) AS cnt FROM users")
+	request.Expect = map[int][]map[string]interface{}{
+		1: {{"id": 1, "username": "Dudi"}, {"id": 2, "username": "Rudi"}},
+		2: {{"cnt": 3}},
+	}
+	sqlResponse := service.RunSQL(request)
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, sqlResponse.RowsAffected)
+	if assert.EqualValues(t, 3, len(sqlResponse.Statements)) {
+		assert.EqualValues(t, 2, sqlResponse.Statements[0].RowsAffected)
+		assert.Nil(t, sqlResponse.Statements[0].Records)
+		assert.EqualValues(t, 2, len(sqlResponse.Statements[1].Records))
+		assert.EqualValues(t, 0, sqlResponse.Statements[1].Validation.FailedCount)
+		assert.EqualValues(t, 1, sqlResponse.Statements[2].Validation.FailedCount)
+	}
+	assert.EqualValues(t, 1, sqlResponse.FailedCount)
+
+	request = dsunit.NewRunSQLRequest("db1", "DELETE FROM users")
+	request.Expect = map[int][]map[string]interface{}{0: {{"id": 1}}}
+	sqlResponse = service.RunSQL(request)
+	assert.EqualValues(t, "error", sqlResponse.Status)
+	queryResponse := service.Query(&dsunit.QueryRequest{Datastore: "db1", SQL: "SELECT COUNT(*) AS cnt FROM users"})
+	if assert.EqualValues(t, 1, len(queryResponse.Records)) {
+		assert.EqualValues(t, 2, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+}
+
+func TestService_RunScriptStatements(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	parent := path.Join(os.TempDir(), "dsunit", "script")
+	_ = os.MkdirAll(parent, 0744)
+	scriptURL := path.Join(parent, "users.sql")
+	SQL := "DELETE FROM users;\n\nINSERT INTO users(id, username) VALUES(1, 'Dudi');\n  INSERT INTO unknown_table(id) VALUES(2);\n"
+	if !assert.Nil(t, ioutil.WriteFile(scriptURL, []byte(SQL), 0644)) {
+		return
+	}
+	var useCases = []struct {
+		description   string
+		transactional bool
+		expectUsers   int
+	}{
+		{
+			description:   "transactional script rollback",
+			transactional: true,
+			expectUsers:   4,
+		},
+		{
+			description: "executed statements stay applied",
+			expectUsers: 1,
+		},
+	}
+	for _, useCase := range useCases {
+		response := service.Prepare(&dsunit.PrepareRequest{
+			DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+		})
+		if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+			return
+		}
+		request := dsunit.NewRunScriptRequest("db1", url.NewResource(scriptURL))
+		request.Transactional = useCase.transactional
+		scriptResponse := service.RunScript(request)
+		assert.EqualValues(t, "error", scriptResponse.Status, useCase.description)
+		assert.True(t, strings.Contains(scriptResponse.Message, "users.sql:4:3: "), useCase.description+": "+scriptResponse.Message)
+		if assert.EqualValues(t, 3, len(scriptResponse.Statements), useCase.description) {
+			assert.EqualValues(t, 1, scriptResponse.Statements[0].Line, useCase.description)
+			assert.EqualValues(t, 4, scriptResponse.Statements[0].RowsAffected, useCase.description)
+			assert.EqualValues(t, 3, scriptResponse.Statements[1].Line, useCase.description)
+			assert.EqualValues(t, 1, scriptResponse.Statements[1].RowsAffected, useCase.description)
+			assert.True(t, scriptResponse.Statements[2].Error != "", useCase.description)
+		}
+		queryResponse := service.Query(&dsunit.QueryRequest{Datastore: "db1", SQL: "SELECT COUNT(*) AS cnt FROM users"})
+		if assert.EqualValues(t, 1, len(queryResponse.Records), useCase.description) {
+			assert.EqualValues(t, useCase.expectUsers, toolbox.AsInt(queryResponse.Records[0]["cnt"]), useCase.description)
+		}
+	}
+}
+
+func TestService_Migrate(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	parent := path.Join(os.TempDir(), "dsunit", "migrations")
+	_ = os.RemoveAll(parent)
+	_ = os.MkdirAll(parent, 0744)
+	var scripts = map[string]string{
+		"V1__create_accounts.sql":     "DROP TABLE IF EXISTS accounts;\nCREATE TABLE accounts (id INTEGER PRIMARY KEY, name VARCHAR(255));\n",
+		"0002_seed_accounts.up.sql":   "INSERT INTO accounts(id, name) VALUES(1, 'Dudi');\n",
+		"0002_seed_accounts.down.sql": "DELETE FROM accounts;\n",
+		"README.md":                   "migrations",
+	}
+	for name, content := range scripts {
+		if !assert.Nil(t, ioutil.WriteFile(path.Join(parent, name), []byte(content), 0644)) {
+			return
+		}
+	}
+	response := service.Migrate(dsunit.NewMigrateRequest("db1", parent))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, len(response.Applied))
+	assert.EqualValues(t, "2", response.Version)
+
+	_ = ioutil.WriteFile(path.Join(parent, "3_rename_account.sql"), []byte("UPDATE accounts SET name = 'Rudi' WHERE id = 1;\n"), 0644)
+	response = service.Migrate(dsunit.NewMigrateRequest("db1", parent))
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		if assert.EqualValues(t, 1, len(response.Applied)) {
+			assert.EqualValues(t, "3_rename_account.sql", response.Applied[0].Script)
+		}
+		assert.EqualValues(t, "3", response.Version)
+	}
+	queryResponse := service.Query(&dsunit.QueryRequest{Datastore: "db1", SQL: "SELECT name FROM accounts"})
+	if assert.EqualValues(t, 1, len(queryResponse.Records)) {
+		assert.EqualValues(t, "Rudi", toolbox.AsString(queryResponse.Records[0]["name"]))
+	}
+
+	_ = ioutil.WriteFile(path.Join(parent, "0002_seed_accounts.up.sql"), []byte("INSERT INTO accounts(id, name) VALUES(2, 'Budi');\n"), 0644)
+	_ = ioutil.WriteFile(path.Join(parent, "4_bad.sql"), []byte("SELECT 1;\nUPDATE unknown_table SET id = 1;\n"), 0644)
+	response = service.Migrate(dsunit.NewMigrateRequest("db1", parent))
+	assert.EqualValues(t, "error", response.Status)
+	if assert.EqualValues(t, 1, len(response.Drifted)) {
+		assert.EqualValues(t, "0002_seed_accounts.up.sql", response.Drifted[0].Script)
+	}
+	assert.EqualValues(t, 0, len(response.Applied))
+
+	_ = ioutil.WriteFile(path.Join(parent, "0002_seed_accounts.up.sql"), []byte(scripts["0002_seed_accounts.up.sql"]), 0644)
+	response = service.Migrate(dsunit.NewMigrateRequest("db1", parent))
+	assert.EqualValues(t, "error", response.Status)
+	assert.True(t, strings.Contains(response.Message, "4_bad.sql:2:1: "), response.Message)
+
+	_ = os.Remove(path.Join(parent, "4_bad.sql"))
+	_ = ioutil.WriteFile(path.Join(parent, "2.5_late.sql"), []byte("UPDATE accounts SET name = 'Late' WHERE id = 1;\n"), 0644)
+	response = service.Migrate(dsunit.NewMigrateRequest("db1", parent))
+	assert.EqualValues(t, "error", response.Status)
+	assert.True(t, strings.Contains(response.Message, "lower than the latest applied version 3"), response.Message)
+	assert.EqualValues(t, 0, len(response.Applied))
+}
+
 func TestService_Prepare(t *testing.T) {
 	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
 	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
@@ -124,6 +285,135 @@ func TestService_Query(t *testing.T) {
 
 }
 
+func TestService_QueryParams(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	response := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	var useCases = []struct {
+		description string
+		request     *dsunit.QueryRequest
+		records     int
+		passed      bool
+	}{
+		{
+			description: "named params with full policy",
+			request: &dsunit.QueryRequest{
+				SQL:           "SELECT id, username FROM users WHERE salary >= :minSalary AND id <> :excluded AND username <> ':excluded'",
+				NamedParams:   map[string]interface{}{"minSalary": 12500, "excluded": 3},
+				ExpectDataset: dsunit.NewDatasetResource("db1", "test/db1/query", "", ""),
+				Dataset:       "high_salary",
+			},
+			records: 2,
+			passed:  true,
+		},
+		{
+			description: "positional params with snapshot policy",
+			request: &dsunit.QueryRequest{
+				SQL:           "SELECT id, username FROM users WHERE salary >= ?",
+				Params:        []interface{}{12500},
+				ExpectDataset: dsunit.NewDatasetResource("db1", "test/db1/query", "", ""),
+				Dataset:       "vudi",
+				CheckPolicy:   dsunit.SnapshotDatasetCheckPolicy,
+			},
+			records: 3,
+			passed:  true,
+		},
+		{
+			description: "expect records are verified without count check",
+			request: &dsunit.QueryRequest{
+				SQL:    "SELECT id, username FROM users WHERE salary >= ?",
+				Params: []interface{}{12500},
+				Expect: []map[string]interface{}{{"@indexBy@": "id"}, {"id": 4, "username": "Vudi"}},
+			},
+			records: 3,
+			passed:  true,
+		},
+		{
+			description: "full policy count mismatch",
+			request: &dsunit.QueryRequest{
+				SQL:           "SELECT id, username FROM users WHERE salary >= ?",
+				Params:        []interface{}{12500},
+				ExpectDataset: dsunit.NewDatasetResource("db1", "test/db1/query", "", ""),
+				Dataset:       "vudi",
+			},
+			records: 3,
+			passed:  false,
+		},
+	}
+	for _, useCase := range useCases {
+		useCase.request.Datastore = "db1"
+		queryResponse := service.Query(useCase.request)
+		if !assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, useCase.description+": "+queryResponse.Message) {
+			continue
+		}
+		assert.EqualValues(t, useCase.records, len(queryResponse.Records), useCase.description)
+		assert.EqualValues(t, useCase.passed, !queryResponse.HasFailure(), useCase.description)
+	}
+	queryResponse := service.Query(&dsunit.QueryRequest{Datastore: "db1", SQL: "SELECT * FROM users WHERE id = :id"})
+	assert.EqualValues(t, "error", queryResponse.Status)
+}
+
+func TestService_QueryExport(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	response := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	parent := path.Join(os.TempDir(), "dsunit", "query")
+	_ = os.MkdirAll(parent, 0744)
+	var useCases = []struct {
+		description string
+		destURL     string
+		format      string
+		expect      string
+	}{
+		{
+			description: "csv export",
+			destURL:     path.Join(parent, "users.csv"),
+			expect:      "id,username\n1,Dudi\n2,Rudi\n3,Budi\n4,Vudi\n",
+		},
+		{
+			description: "ndjson export",
+			destURL:     path.Join(parent, "users.out"),
+			format:      "ndjson",
+			expect:      "{\"id\":1,\"username\":\"Dudi\"}\n{\"id\":2,\"username\":\"Rudi\"}\n{\"id\":3,\"username\":\"Budi\"}\n{\"id\":4,\"username\":\"Vudi\"}\n",
+		},
+	}
+	for _, useCase := range useCases {
+		_ = os.Remove(useCase.destURL)
+		queryResponse := service.Query(&dsunit.QueryRequest{
+			Datastore: "db1",
+			SQL:       "SELECT id, username FROM users WHERE id > ? ORDER BY id",
+			Params:    []interface{}{0},
+			DestURL:   useCase.destURL,
+			Format:    useCase.format,
+		})
+		if !assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, useCase.description+": "+queryResponse.Message) {
+			continue
+		}
+		assert.EqualValues(t, 4, queryResponse.Count, useCase.description)
+		assert.EqualValues(t, 0, len(queryResponse.Records), useCase.description)
+		content, err := ioutil.ReadFile(useCase.destURL)
+		if assert.Nil(t, err, useCase.description) {
+			assert.EqualValues(t, useCase.expect, string(content), useCase.description)
+		}
+	}
+	queryResponse := service.Query(&dsunit.QueryRequest{Datastore: "db1", SQL: "SELECT * FROM users", DestURL: path.Join(parent, "users.xml"), Format: "xml"})
+	assert.EqualValues(t, "error", queryResponse.Status)
+}
+
 func TestService_FromQueryValidation(t *testing.T) {
 	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
 	if assert.Nil(t, err) {
@@ -164,6 +454,101 @@ func TestService_GetSequences(t *testing.T) {
 	}
 }
 
+func TestService_SyncSequences(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	response := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	resetSequence := func() {
+		sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "UPDATE sqlite_sequence SET seq = 1 WHERE name = 'users'"))
+		assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message)
+		assert.EqualValues(t, 2, service.Sequence(dsunit.NewSequenceRequest("db1", "users")).Sequences["users"])
+	}
+
+	resetSequence()
+	syncResponse := service.SyncSequences(dsunit.NewSyncSequencesRequest("db1", "users"))
+	if assert.EqualValues(t, dsunit.StatusOk, syncResponse.Status, syncResponse.Message) {
+		assert.EqualValues(t, 5, syncResponse.Sequences["users"])
+		assert.EqualValues(t, 5, service.Sequence(dsunit.NewSequenceRequest("db1", "users")).Sequences["users"])
+	}
+
+	resetSequence()
+	response = service.Prepare(&dsunit.PrepareRequest{
+		SyncSequences:   true,
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+	})
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, 5, response.Sequences["users"])
+		assert.EqualValues(t, 5, service.Sequence(dsunit.NewSequenceRequest("db1", "users")).Sequences["users"])
+	}
+
+	syncResponse = service.SyncSequences(dsunit.NewSyncSequencesRequest("db1"))
+	assert.EqualValues(t, "error", syncResponse.Status)
+}
+
+func TestService_PingReadiness(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	response := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	var useCases = []struct {
+		description string
+		readiness   *dsunit.Readiness
+		ready       bool
+	}{
+		{
+			description: "ping only",
+			ready:       true,
+		},
+		{
+			description: "all conditions met",
+			readiness: &dsunit.Readiness{
+				SQL:           "SELECT 1",
+				Tables:        []string{"users", "products"},
+				MinRowCount:   map[string]int{"users": 4},
+				SchemaVersion: &dsunit.SchemaVersion{Table: "users", Column: "username", Value: "Vudi"},
+			},
+			ready: true,
+		},
+		{
+			description: "missing table",
+			readiness:   &dsunit.Readiness{Tables: []string{"schema_history"}},
+		},
+		{
+			description: "not enough rows",
+			readiness:   &dsunit.Readiness{MinRowCount: map[string]int{"users": 5}},
+		},
+		{
+			description: "missing schema version",
+			readiness:   &dsunit.Readiness{SchemaVersion: &dsunit.SchemaVersion{Table: "users", Column: "username", Value: "Zudi"}},
+		},
+	}
+	for _, useCase := range useCases {
+		pingResponse := service.Ping(&dsunit.PingRequest{
+			Datastores: map[string]*dsunit.Readiness{"db1": useCase.readiness},
+			TimeoutMs:  50,
+			Backoff:    &dsunit.PingBackoff{DelayMs: 10, Multiplier: 2},
+		})
+		assert.EqualValues(t, useCase.ready, pingResponse.Status == dsunit.StatusOk, useCase.description+": "+pingResponse.Message)
+		_, notReady := pingResponse.NotReady["db1"]
+		assert.EqualValues(t, !useCase.ready, notReady, useCase.description)
+	}
+	pingResponse := service.Ping(&dsunit.PingRequest{Datastore: "db1", Datastores: map[string]*dsunit.Readiness{"unknown": nil}})
+	assert.EqualValues(t, "error", pingResponse.Status)
+}
+
 func TestService_FreezeDataset(t *testing.T) {
 	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
 
@@ -188,6 +573,206 @@ func TestService_FreezeDataset(t *testing.T) {
 	}
 }
 
+func TestService_FreezeKeyDirective(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	prepareResponse := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	response := service.Freeze(&dsunit.FreezeRequest{
+		Datastore:    "db1",
+		DestURL:      "/tmp/dsunit/users_keys.json",
+		SQL:          "SELECT * FROM users",
+		KeyDirective: true,
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 5, response.Count)
+	content, err := ioutil.ReadFile("/tmp/dsunit/users_keys.json")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var records = make([]map[string]interface{}, 0)
+	if assert.Nil(t, json.Unmarshal(content, &records)) && assert.EqualValues(t, 5, len(records)) {
+		assert.EqualValues(t, map[string]interface{}{dsunit.AutoincrementDirective: "id"}, records[0])
+	}
+}
+
+func TestService_FreezeSymbolicIDs(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1",
+		"DELETE FROM order_lines",
+		"DELETE FROM products",
+		"INSERT INTO products(name, price) VALUES('p1', 1.5)",
+		"INSERT INTO products(name, price) VALUES('p2', 2.5)",
+		"INSERT INTO order_lines(order_id, seq, product_id, quantity) SELECT 1, 1, MIN(id), 1 FROM products",
+		"INSERT INTO order_lines(order_id, seq, product_id, quantity) SELECT 1, 2, MAX(id), 2 FROM products"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	parent := path.Join(os.TempDir(), "dsunit", "symbolic")
+	_ = os.RemoveAll(parent)
+	for _, request := range []*dsunit.FreezeRequest{
+		{Datastore: "db1", DestURL: path.Join(parent, "seed_products.json"), SQL: "SELECT id, name, price FROM products ORDER BY id", SymbolicIDs: true},
+		{Datastore: "db1", DestURL: path.Join(parent, "seed_order_lines.json"), SQL: "SELECT id, order_id, seq, product_id, quantity FROM order_lines ORDER BY id", SymbolicIDs: true, References: map[string]string{"product_id": "products"}},
+	} {
+		response := service.Freeze(request)
+		if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+			return
+		}
+	}
+	content, err := ioutil.ReadFile(path.Join(parent, "seed_order_lines.json"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	var records = make([]map[string]interface{}, 0)
+	if assert.Nil(t, json.Unmarshal(content, &records)) && assert.EqualValues(t, 2, len(records)) {
+		assert.EqualValues(t, `<ds:seq["order_lines", 0]>`, records[0]["id"])
+		assert.EqualValues(t, `<ds:seq["products", 0]>`, records[0]["product_id"])
+		assert.EqualValues(t, `<ds:seq["order_lines", 1]>`, records[1]["id"])
+		assert.EqualValues(t, `<ds:seq["products", 1]>`, records[1]["product_id"])
+	}
+
+	sqlResponse = service.RunSQL(dsunit.NewRunSQLRequest("db1", "DELETE FROM order_lines", "DELETE FROM products"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	prepareResponse := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", parent+"/", "seed_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	queryResponse := service.Query(&dsunit.QueryRequest{Datastore: "db1", SQL: "SELECT p.name, o.quantity FROM order_lines o JOIN products p ON o.product_id = p.id ORDER BY o.seq"})
+	if assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message) && assert.EqualValues(t, 2, len(queryResponse.Records)) {
+		assert.EqualValues(t, "p1", queryResponse.Records[0]["name"])
+		assert.EqualValues(t, "p2", queryResponse.Records[1]["name"])
+	}
+}
+
+func TestService_Scaffold(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	prepareResponse := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	os.RemoveAll("/tmp/dsunit/scaffold")
+	response := service.Scaffold(&dsunit.ScaffoldRequest{
+		Datastore: "db1",
+		Tables:    []string{"users"},
+		Test:      "TestUserService_Create",
+		DestURL:   "/tmp/dsunit/scaffold",
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, []string{
+		"file:///tmp/dsunit/scaffold/user_service_test_create_prepare_users.json",
+		"file:///tmp/dsunit/scaffold/user_service_test_create_expect_users.json",
+	}, response.Datasets)
+	assert.EqualValues(t, "file:///tmp/dsunit/scaffold/user_service_test.go", response.TestURL)
+	content, err := ioutil.ReadFile("/tmp/dsunit/scaffold/user_service_test.go")
+	if assert.Nil(t, err) {
+		assert.True(t, strings.Contains(string(content), "package scaffold"), string(content))
+		assert.True(t, strings.Contains(string(content), `dsunit.PrepareDatastore(t, "db1")`), string(content))
+		assert.True(t, strings.Contains(string(content), `"github.com/mattn/go-sqlite3"`), string(content))
+	}
+}
+
+func TestService_Clone(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	prepareResponse := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	filename := "/tmp/dsunit/clone/db2.db"
+	_ = os.MkdirAll(path.Dir(filename), 0744)
+	_ = toolbox.RemoveFileIfExist(filename)
+	registerResponse := service.Register(dsunit.NewRegisterRequest("db2", &dsc.Config{
+		DriverName: "sqlite3",
+		Descriptor: "[url]",
+		Parameters: map[string]interface{}{"url": filename},
+	}))
+	if !assert.EqualValues(t, dsunit.StatusOk, registerResponse.Status, registerResponse.Message) {
+		return
+	}
+	response := service.Clone(&dsunit.CloneRequest{
+		Datastore:     "db1",
+		DestDatastore: "db2",
+		Tables:        []string{"users"},
+		BatchSize:     3,
+		Transform:     &dsunit.FreezeRequest{Override: map[string]string{"comments": "n/a"}},
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.Equal(t, 1, len(response.DDLs))
+	assert.Equal(t, 4, response.Counts["users"])
+	var records = make([]map[string]interface{}, 0)
+	err = service.Registry().Get("db2").ReadAll(&records, "SELECT id, comments FROM users ORDER BY id", nil, nil)
+	if assert.Nil(t, err) && assert.Equal(t, 4, len(records)) {
+		assert.EqualValues(t, 1, toolbox.AsInt(records[0]["id"]))
+		assert.EqualValues(t, "n/a", toolbox.AsString(records[0]["comments"]))
+	}
+}
+
+func TestService_DumpData(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	prepareResponse := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	response := service.Dump(&dsunit.DumpRequest{
+		Datastore: "db1",
+		Tables:    []string{"users"},
+		Mode:      dsunit.DumpModeData,
+		Criteria:  map[string]string{"users": "id > 1"},
+		BatchSize: 2,
+		DestURL:   "/tmp/dsunit/dump/users.sql",
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.Equal(t, 2, response.Count)
+	content, err := ioutil.ReadFile("/tmp/dsunit/dump/users.sql")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(string(content), "INSERT INTO users(id, username, active, salary, comments, last_access_time) VALUES\n\t(2, 'Rudi'"), string(content))
+	runResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "DELETE FROM users"))
+	if !assert.EqualValues(t, dsunit.StatusOk, runResponse.Status, runResponse.Message) {
+		return
+	}
+	scriptResponse := service.RunScript(dsunit.NewRunScriptRequest("db1", url.NewResource("/tmp/dsunit/dump/users.sql")))
+	if assert.EqualValues(t, dsunit.StatusOk, scriptResponse.Status, scriptResponse.Message) {
+		assert.Equal(t, 3, scriptResponse.RowsAffected)
+	}
+}
+
 func TestService_Compare(t *testing.T) {
 	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
 	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
@@ -239,3 +824,227 @@ func TestService_Compare(t *testing.T) {
 	}
 
 }
+
+func TestService_CompareStream(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data", "test1_prepare_", ""),
+	})
+	for _, ordered := range []bool{true, false} {
+		response := service.Compare(&dsunit.CompareRequest{
+			Source1: &dsunit.DatastoreSQL{
+				Datastore: "db1",
+				SQL:       "SELECT * FROM users WHERE id <> 2 ORDER BY id",
+			},
+			Source2: &dsunit.DatastoreSQL{
+				Datastore: "db1",
+				SQL:       "SELECT * FROM users WHERE id <> 3 ORDER BY id",
+			},
+			Directives: map[string]interface{}{
+				assertly.IndexByDirective: "id",
+			},
+			Stream:         true,
+			Ordered:        ordered,
+			SortBufferSize: 2,
+		})
+		if !assert.EqualValues(t, "ok", response.Status, response.Message) {
+			continue
+		}
+		assert.EqualValues(t, 3, response.Dataset1Count)
+		assert.EqualValues(t, 3, response.Dataset2Count)
+		assert.EqualValues(t, 2, response.MatchedRows)
+		assert.EqualValues(t, 2, response.FailedCount)
+	}
+	response := service.Compare(&dsunit.CompareRequest{
+		Source1: &dsunit.DatastoreSQL{Datastore: "db1", SQL: "SELECT * FROM users ORDER BY id DESC"},
+		Source2: &dsunit.DatastoreSQL{Datastore: "db1", SQL: "SELECT * FROM users ORDER BY id DESC"},
+		Directives: map[string]interface{}{
+			assertly.IndexByDirective: "id",
+		},
+		Stream:  true,
+		Ordered: true,
+	})
+	assert.EqualValues(t, "error", response.Status)
+}
+
+func TestService_CompareChecksum(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data", "test1_prepare_", ""),
+	})
+	response := service.Compare(&dsunit.CompareRequest{
+		Source1: &dsunit.DatastoreSQL{
+			Datastore: "db1",
+			SQL:       "SELECT id, username, salary FROM users",
+		},
+		Source2: &dsunit.DatastoreSQL{
+			Datastore: "db1",
+			SQL:       "SELECT id, username, CASE WHEN id = 3 THEN 0 ELSE salary END AS salary FROM users",
+		},
+		Directives: map[string]interface{}{
+			assertly.IndexByDirective: "id",
+		},
+		Checksum: &dsunit.CompareChecksum{
+			Columns:       []string{"id", "username", "salary"},
+			KeyColumn:     "id",
+			PartitionSize: 2,
+		},
+	})
+	if !assert.EqualValues(t, "ok", response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 4, response.Dataset1Count)
+	assert.EqualValues(t, 3, response.PartitionCount)
+	assert.EqualValues(t, []string{"1"}, response.DiscrepantPartitions)
+	assert.EqualValues(t, 3, response.MatchedRows)
+	assert.EqualValues(t, 1, response.FailedCount)
+}
+
+func TestService_CompareColumnMapping(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data", "test1_prepare_", ""),
+	})
+	two := 2
+	response := service.Compare(&dsunit.CompareRequest{
+		Source1: &dsunit.DatastoreSQL{
+			Datastore: "db1",
+			SQL:       "SELECT id, ' ' || UPPER(username) AS name, CAST(salary AS TEXT) AS amount FROM users",
+		},
+		Source2: &dsunit.DatastoreSQL{
+			Datastore: "db1",
+			SQL:       "SELECT id, username, salary + 0.004 AS salary FROM users",
+		},
+		Directives: map[string]interface{}{
+			assertly.IndexByDirective: "id",
+		},
+		ColumnMapping: map[string]string{"name": "username", "amount": "salary"},
+		Coercions: map[string]*dsunit.ColumnCoercion{
+			"username": {Trim: true, Case: dsunit.CoercionLowerCase},
+			"salary":   {Round: &two},
+		},
+		MaxRowDiscrepancy: 10,
+	})
+	if !assert.EqualValues(t, "ok", response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 4, response.MatchedRows)
+	assert.EqualValues(t, 0, response.FailedCount)
+}
+
+func TestService_CompareDiscrepancyURL(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data", "test1_prepare_", ""),
+	})
+	reportURL := "/tmp/dsunit/compare/discrepancies.csv"
+	_ = os.MkdirAll(path.Dir(reportURL), 0744)
+	_ = os.Remove(reportURL)
+	for _, stream := range []bool{false, true} {
+		response := service.Compare(&dsunit.CompareRequest{
+			Source1: &dsunit.DatastoreSQL{
+				Datastore: "db1",
+				SQL:       "SELECT id, username, salary FROM users WHERE id <> 4",
+			},
+			Source2: &dsunit.DatastoreSQL{
+				Datastore: "db1",
+				SQL:       "SELECT id, username, CASE WHEN id = 2 THEN 0 ELSE salary END AS salary FROM users WHERE id <> 3",
+			},
+			Directives: map[string]interface{}{
+				assertly.IndexByDirective: "id",
+			},
+			Stream:            stream,
+			MaxRowDiscrepancy: 1,
+			DiscrepancyURL:    reportURL,
+		})
+		if !assert.EqualValues(t, "ok", response.Status, response.Message) {
+			continue
+		}
+		assert.EqualValues(t, 1, response.MatchedRows)
+		assert.EqualValues(t, 1, response.MismatchedRows)
+		assert.EqualValues(t, 1, response.MissingRows)
+		assert.EqualValues(t, 1, response.ExtraRows)
+		assert.EqualValues(t, 1, response.FailedCount)
+		content, err := ioutil.ReadFile(reportURL)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		if assert.EqualValues(t, 4, len(lines)) {
+			assert.EqualValues(t, "type,id,columns,source1,source2", lines[0])
+			assert.True(t, strings.HasPrefix(lines[1], "mismatch,2,salary,"))
+			assert.True(t, strings.HasPrefix(lines[2], "missing,3,,"))
+			assert.True(t, strings.HasPrefix(lines[3], "extra,4,,,"))
+		}
+	}
+}
+
+func TestService_CompareBatch(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data", "test1_prepare_", ""),
+	})
+	manifestURL := "/tmp/dsunit/compare/manifest.json"
+	_ = os.MkdirAll(path.Dir(manifestURL), 0744)
+	manifest := `{
+	"Compares": {
+		"salary": {
+			"Source1": {"Datastore": "db1", "SQL": "SELECT id, salary FROM users"},
+			"Source2": {"Datastore": "db1", "SQL": "SELECT id, salary + 1 AS salary FROM users"}
+		},
+		"missing": {
+			"Source1": {"Datastore": "db1", "SQL": "SELECT id FROM users"},
+			"Source2": {"Datastore": "db3", "SQL": "SELECT id FROM users"}
+		}
+	},
+	"Directives": {"@indexBy@": "id"},
+	"MaxRowDiscrepancy": 10
+}`
+	if !assert.Nil(t, ioutil.WriteFile(manifestURL, []byte(manifest), 0644)) {
+		return
+	}
+	response := service.CompareBatch(&dsunit.CompareBatchRequest{
+		ManifestURL: manifestURL,
+		Compares: map[string]*dsunit.CompareRequest{
+			"users": {
+				Source1: &dsunit.DatastoreSQL{Datastore: "db1", SQL: "SELECT * FROM users"},
+				Source2: &dsunit.DatastoreSQL{Datastore: "db1", SQL: "SELECT * FROM users"},
+				Stream:  true,
+			},
+		},
+		Ignore:      []string{"comments"},
+		Concurrency: 2,
+	})
+	assert.EqualValues(t, "error", response.Status)
+	assert.False(t, response.Passed)
+	assert.EqualValues(t, 1, response.PassedCount)
+	assert.EqualValues(t, 2, response.FailedCount)
+	if !assert.EqualValues(t, 3, len(response.Summaries)) {
+		return
+	}
+	var summaries = make(map[string]*dsunit.CompareSummary)
+	for _, summary := range response.Summaries {
+		summaries[summary.Name] = summary
+	}
+	assert.EqualValues(t, "missing", response.Summaries[0].Name)
+	assert.EqualValues(t, "error", summaries["missing"].Status)
+	assert.EqualValues(t, 4, summaries["salary"].MismatchedRows)
+	assert.EqualValues(t, 0, summaries["salary"].MatchedRows)
+	assert.True(t, summaries["users"].Passed)
+	assert.EqualValues(t, 4, summaries["users"].MatchedRows)
+}
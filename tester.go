@@ -155,6 +155,9 @@ func (s *localTester) RecreateFromURL(t *testing.T, URL string) bool {
 // RunSQL runs supplied SQL
 func (s *localTester) RunSQL(t *testing.T, request *RunSQLRequest) bool {
 	response := s.service.RunSQL(request)
+	if response.Status == StatusOk && response.FailedCount > 0 {
+		response.SetError(fmt.Errorf("%v expected records assertion(s) failed", response.FailedCount))
+	}
 	return handleResponse(t, response.BaseResponse)
 }
 
@@ -0,0 +1,382 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/dsc"
+	dsurl "github.com/viant/dsunit/url"
+	"github.com/viant/toolbox"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TypeMappingRule represents data type mapping rule i.e. DECIMAL(p,s) -> NUMERIC(p,s)
+type TypeMappingRule struct {
+	Source       string `description:"source type pattern, lower case parameters are placeholders i.e. VARCHAR(n), type without parameters matches any parameters"`
+	Target       string `description:"target type, placeholders are replaced with matched source parameters i.e. STRING(n)"`
+	SourceVendor string `description:"source vendor or driver i.e. mysql, any if empty"`
+	Column       string `description:"column name pattern i.e. *id, any if empty"`
+	source       *typePattern
+}
+
+// Init parses source type pattern
+func (r *TypeMappingRule) Init() error {
+	if strings.TrimSpace(r.Source) == "" || strings.TrimSpace(r.Target) == "" {
+		return fmt.Errorf("invalid type mapping rule: %v -> %v", r.Source, r.Target)
+	}
+	name, params := parseType(r.Source)
+	r.source = &typePattern{name: name, params: params}
+	return nil
+}
+
+// Map returns target type if rule matches source column type
+func (r *TypeMappingRule) Map(sourceVendor, column, dbType string) (string, bool) {
+	if r.SourceVendor != "" && typeVendor(r.SourceVendor) != sourceVendor {
+		return "", false
+	}
+	if r.Column != "" {
+		if matched, _ := path.Match(strings.ToLower(r.Column), strings.ToLower(column)); !matched {
+			return "", false
+		}
+	}
+	name, params := parseType(dbType)
+	captured, ok := r.source.match(name, params)
+	if !ok {
+		return "", false
+	}
+	return expandType(r.Target, captured), true
+}
+
+// NewTypeMappingRule creates a rule from SOURCE -> TARGET expression
+func NewTypeMappingRule(expression string) (*TypeMappingRule, error) {
+	pair := strings.Split(expression, "->")
+	if len(pair) != 2 {
+		return nil, fmt.Errorf("invalid type mapping rule: %v, expected SOURCE -> TARGET", expression)
+	}
+	result := &TypeMappingRule{Source: strings.TrimSpace(pair[0]), Target: strings.TrimSpace(pair[1])}
+	return result, result.Init()
+}
+
+var vendorAliases = map[string]string{
+	"postgresql": "postgres",
+	"pgx":        "postgres",
+	"pq":         "postgres",
+	"sqlite3":    "sqlite",
+	"ora":        "oracle",
+	"oci8":       "oracle",
+	"godror":     "oracle",
+	"cql":        "cassandra",
+}
+
+// typeVendor returns vendor for supplied driver name
+func typeVendor(driver string) string {
+	driver = strings.ToLower(strings.TrimSpace(driver))
+	if vendor, ok := vendorAliases[driver]; ok {
+		return vendor
+	}
+	return driver
+}
+
+// defaultTypeMappings represents default rules per target vendor, the first matching rule wins, unmatched types are kept as is
+var defaultTypeMappings = map[string][]string{
+	"mysql": {
+		"BOOLEAN -> TINYINT(1)", "BOOL -> TINYINT(1)", "BIT -> TINYINT(1)",
+		"INTEGER -> INT", "INT64 -> BIGINT", "SERIAL -> INT", "BIGSERIAL -> BIGINT", "SMALLSERIAL -> SMALLINT", "COUNTER -> BIGINT", "VARINT -> DECIMAL(38,0)",
+		"NUMERIC(p,s) -> DECIMAL(p,s)", "NUMBER(p,s) -> DECIMAL(p,s)", "NUMBER(p) -> DECIMAL(p,0)", "NUMERIC -> DECIMAL(38,9)", "NUMBER -> DECIMAL(38,9)", "BIGNUMERIC -> DECIMAL(65,30)",
+		"FLOAT64 -> DOUBLE", "DOUBLE PRECISION -> DOUBLE", "REAL -> FLOAT", "BINARY_DOUBLE -> DOUBLE", "BINARY_FLOAT -> FLOAT",
+		"CHARACTER VARYING(n) -> VARCHAR(n)", "VARCHAR2(n) -> VARCHAR(n)", "NVARCHAR2(n) -> VARCHAR(n)", "STRING(n) -> VARCHAR(n)", "CHARACTER(n) -> CHAR(n)",
+		"CHARACTER VARYING -> TEXT", "VARCHAR -> VARCHAR(255)", "VARCHAR2 -> VARCHAR(255)", "STRING -> VARCHAR(255)", "ASCII -> TEXT", "LONG VARCHAR -> LONGTEXT", "CLOB -> LONGTEXT",
+		"BYTEA -> LONGBLOB", "BYTES -> LONGBLOB", "LONG VARBINARY -> LONGBLOB", "RAW(n) -> VARBINARY(n)",
+		"TIMESTAMP(p) WITH TIME ZONE -> TIMESTAMP(p)", "TIMESTAMP WITH TIME ZONE -> TIMESTAMP", "TIMESTAMPTZ -> TIMESTAMP", "TIMESTAMP(p) WITHOUT TIME ZONE -> DATETIME(p)", "TIMESTAMP WITHOUT TIME ZONE -> DATETIME",
+		"UUID -> CHAR(36)", "TIMEUUID -> CHAR(36)", "INET -> VARCHAR(45)", "JSONB -> JSON",
+	},
+	"postgres": {
+		"TINYINT(1) -> BOOLEAN", "BIT -> BOOLEAN", "BOOL -> BOOLEAN", "TINYINT -> SMALLINT", "MEDIUMINT -> INTEGER", "INT -> INTEGER", "INT64 -> BIGINT", "COUNTER -> BIGINT", "VARINT -> NUMERIC",
+		"DECIMAL(p,s) -> NUMERIC(p,s)", "NUMBER(p,s) -> NUMERIC(p,s)", "NUMBER(p) -> NUMERIC(p)", "DECIMAL -> NUMERIC", "NUMBER -> NUMERIC", "BIGNUMERIC -> NUMERIC",
+		"FLOAT64 -> DOUBLE PRECISION", "DOUBLE -> DOUBLE PRECISION", "BINARY_DOUBLE -> DOUBLE PRECISION", "FLOAT -> REAL", "BINARY_FLOAT -> REAL",
+		"VARCHAR2(n) -> VARCHAR(n)", "NVARCHAR2(n) -> VARCHAR(n)", "STRING(n) -> VARCHAR(n)", "STRING -> TEXT", "VARCHAR2 -> TEXT", "ASCII -> TEXT", "LONG VARCHAR -> TEXT",
+		"TINYTEXT -> TEXT", "MEDIUMTEXT -> TEXT", "LONGTEXT -> TEXT", "CLOB -> TEXT",
+		"BLOB -> BYTEA", "TINYBLOB -> BYTEA", "MEDIUMBLOB -> BYTEA", "LONGBLOB -> BYTEA", "BYTES -> BYTEA", "LONG VARBINARY -> BYTEA", "RAW -> BYTEA",
+		"DATETIME(p) -> TIMESTAMP(p)", "DATETIME -> TIMESTAMP", "TIMEUUID -> UUID",
+	},
+	"sqlite": {
+		"TINYINT(1) -> BOOLEAN", "BIT -> BOOLEAN", "BOOL -> BOOLEAN",
+		"TINYINT -> INTEGER", "SMALLINT -> INTEGER", "MEDIUMINT -> INTEGER", "INT -> INTEGER", "BIGINT -> INTEGER", "INT64 -> INTEGER",
+		"SERIAL -> INTEGER", "BIGSERIAL -> INTEGER", "SMALLSERIAL -> INTEGER", "COUNTER -> INTEGER", "VARINT -> INTEGER",
+		"NUMERIC(p,s) -> DECIMAL(p,s)", "NUMBER(p,s) -> DECIMAL(p,s)", "NUMBER -> NUMERIC", "BIGNUMERIC -> NUMERIC",
+		"FLOAT64 -> REAL", "DOUBLE -> REAL", "DOUBLE PRECISION -> REAL", "FLOAT -> REAL", "BINARY_DOUBLE -> REAL", "BINARY_FLOAT -> REAL",
+		"CHARACTER VARYING(n) -> VARCHAR(n)", "VARCHAR2(n) -> VARCHAR(n)", "NVARCHAR2(n) -> VARCHAR(n)", "STRING(n) -> VARCHAR(n)",
+		"CHARACTER VARYING -> TEXT", "STRING -> TEXT", "ASCII -> TEXT", "LONG VARCHAR -> TEXT", "TINYTEXT -> TEXT", "MEDIUMTEXT -> TEXT", "LONGTEXT -> TEXT", "CLOB -> TEXT", "JSON -> TEXT", "JSONB -> TEXT", "INET -> TEXT",
+		"BYTEA -> BLOB", "BYTES -> BLOB", "LONGBLOB -> BLOB", "LONG VARBINARY -> BLOB",
+		"TIMESTAMP(p) WITH TIME ZONE -> TIMESTAMP", "TIMESTAMP WITH TIME ZONE -> TIMESTAMP", "TIMESTAMPTZ -> TIMESTAMP", "TIMESTAMP(p) -> TIMESTAMP", "DATETIME(p) -> DATETIME",
+		"UUID -> VARCHAR(36)", "TIMEUUID -> VARCHAR(36)",
+	},
+	"oracle": {
+		"TINYINT(1) -> NUMBER(1)", "BOOLEAN -> NUMBER(1)", "BOOL -> NUMBER(1)", "BIT -> NUMBER(1)",
+		"TINYINT -> NUMBER(3)", "SMALLINT -> NUMBER(5)", "MEDIUMINT -> NUMBER(7)", "INT -> NUMBER(10)", "INTEGER -> NUMBER(10)", "SERIAL -> NUMBER(10)",
+		"BIGINT -> NUMBER(19)", "INT64 -> NUMBER(19)", "BIGSERIAL -> NUMBER(19)", "COUNTER -> NUMBER(19)", "VARINT -> NUMBER",
+		"DECIMAL(p,s) -> NUMBER(p,s)", "NUMERIC(p,s) -> NUMBER(p,s)", "DECIMAL -> NUMBER", "NUMERIC -> NUMBER", "BIGNUMERIC -> NUMBER",
+		"FLOAT64 -> BINARY_DOUBLE", "DOUBLE -> BINARY_DOUBLE", "DOUBLE PRECISION -> BINARY_DOUBLE", "FLOAT -> BINARY_FLOAT", "REAL -> BINARY_FLOAT",
+		"VARCHAR(n) -> VARCHAR2(n)", "CHARACTER VARYING(n) -> VARCHAR2(n)", "STRING(n) -> VARCHAR2(n)", "CHARACTER(n) -> CHAR(n)",
+		"VARCHAR -> VARCHAR2(4000)", "CHARACTER VARYING -> VARCHAR2(4000)", "STRING -> VARCHAR2(4000)", "ASCII -> VARCHAR2(4000)", "INET -> VARCHAR2(45)",
+		"TEXT -> CLOB", "TINYTEXT -> CLOB", "MEDIUMTEXT -> CLOB", "LONGTEXT -> CLOB", "LONG VARCHAR -> CLOB", "JSON -> CLOB", "JSONB -> CLOB",
+		"BYTEA -> BLOB", "BYTES -> BLOB", "LONGBLOB -> BLOB", "LONG VARBINARY -> BLOB",
+		"DATETIME(p) -> TIMESTAMP(p)", "DATETIME -> TIMESTAMP", "TIMESTAMPTZ -> TIMESTAMP WITH TIME ZONE", "UUID -> VARCHAR2(36)", "TIMEUUID -> VARCHAR2(36)",
+	},
+	"vertica": {
+		"TINYINT(1) -> BOOLEAN", "BIT -> BOOLEAN", "BOOL -> BOOLEAN",
+		"TINYINT -> INT", "SMALLINT -> INT", "MEDIUMINT -> INT", "INTEGER -> INT", "BIGINT -> INT", "INT64 -> INT", "SERIAL -> INT", "BIGSERIAL -> INT", "COUNTER -> INT", "VARINT -> NUMERIC",
+		"DECIMAL(p,s) -> NUMERIC(p,s)", "NUMBER(p,s) -> NUMERIC(p,s)", "DECIMAL -> NUMERIC", "NUMBER -> NUMERIC", "BIGNUMERIC -> NUMERIC",
+		"FLOAT64 -> FLOAT", "DOUBLE -> FLOAT", "DOUBLE PRECISION -> FLOAT", "REAL -> FLOAT", "BINARY_DOUBLE -> FLOAT", "BINARY_FLOAT -> FLOAT",
+		"CHARACTER VARYING(n) -> VARCHAR(n)", "VARCHAR2(n) -> VARCHAR(n)", "STRING(n) -> VARCHAR(n)",
+		"STRING -> VARCHAR(65000)", "ASCII -> VARCHAR(65000)", "INET -> VARCHAR(45)",
+		"TEXT -> LONG VARCHAR", "TINYTEXT -> LONG VARCHAR", "MEDIUMTEXT -> LONG VARCHAR", "LONGTEXT -> LONG VARCHAR", "CLOB -> LONG VARCHAR", "JSON -> LONG VARCHAR", "JSONB -> LONG VARCHAR",
+		"BLOB -> LONG VARBINARY", "LONGBLOB -> LONG VARBINARY", "BYTEA -> LONG VARBINARY", "BYTES -> LONG VARBINARY",
+		"DATETIME(p) -> TIMESTAMP(p)", "DATETIME -> TIMESTAMP", "TIMESTAMP(p) WITH TIME ZONE -> TIMESTAMPTZ(p)", "TIMESTAMP WITH TIME ZONE -> TIMESTAMPTZ", "TIMEUUID -> UUID",
+	},
+	"bigquery": {
+		"TINYINT(1) -> BOOL", "BOOLEAN -> BOOL", "BIT -> BOOL",
+		"TINYINT -> INT64", "SMALLINT -> INT64", "MEDIUMINT -> INT64", "INT -> INT64", "INTEGER -> INT64", "BIGINT -> INT64", "SERIAL -> INT64", "BIGSERIAL -> INT64", "SMALLSERIAL -> INT64", "COUNTER -> INT64", "VARINT -> BIGNUMERIC",
+		"DECIMAL(p,s) -> NUMERIC(p,s)", "NUMBER(p,s) -> NUMERIC(p,s)", "NUMBER(p) -> NUMERIC(p)", "DECIMAL -> NUMERIC", "NUMBER -> NUMERIC",
+		"FLOAT -> FLOAT64", "DOUBLE -> FLOAT64", "DOUBLE PRECISION -> FLOAT64", "REAL -> FLOAT64", "BINARY_DOUBLE -> FLOAT64", "BINARY_FLOAT -> FLOAT64",
+		"VARCHAR(n) -> STRING(n)", "CHAR(n) -> STRING(n)", "CHARACTER VARYING(n) -> STRING(n)", "CHARACTER(n) -> STRING(n)", "VARCHAR2(n) -> STRING(n)", "NVARCHAR2(n) -> STRING(n)",
+		"VARCHAR -> STRING", "CHAR -> STRING", "CHARACTER VARYING -> STRING", "VARCHAR2 -> STRING", "TEXT -> STRING", "TINYTEXT -> STRING", "MEDIUMTEXT -> STRING", "LONGTEXT -> STRING",
+		"CLOB -> STRING", "LONG VARCHAR -> STRING", "ASCII -> STRING", "UUID -> STRING", "TIMEUUID -> STRING", "INET -> STRING", "JSONB -> JSON",
+		"BLOB -> BYTES", "LONGBLOB -> BYTES", "BYTEA -> BYTES", "VARBINARY(n) -> BYTES(n)", "RAW(n) -> BYTES(n)", "LONG VARBINARY -> BYTES",
+		"TIMESTAMP WITH TIME ZONE -> TIMESTAMP", "TIMESTAMPTZ -> TIMESTAMP", "TIMESTAMP WITHOUT TIME ZONE -> DATETIME",
+	},
+	"cassandra": {
+		"TINYINT(1) -> BOOLEAN", "BIT -> BOOLEAN", "BOOL -> BOOLEAN",
+		"MEDIUMINT -> INT", "INTEGER -> INT", "SERIAL -> INT", "INT64 -> BIGINT", "BIGSERIAL -> BIGINT", "SMALLSERIAL -> SMALLINT",
+		"NUMERIC -> DECIMAL", "NUMBER -> DECIMAL", "BIGNUMERIC -> DECIMAL",
+		"FLOAT64 -> DOUBLE", "DOUBLE PRECISION -> DOUBLE", "BINARY_DOUBLE -> DOUBLE", "REAL -> FLOAT", "BINARY_FLOAT -> FLOAT",
+		"VARCHAR -> TEXT", "CHAR -> TEXT", "CHARACTER VARYING -> TEXT", "CHARACTER -> TEXT", "VARCHAR2 -> TEXT", "NVARCHAR2 -> TEXT", "STRING -> TEXT",
+		"TINYTEXT -> TEXT", "MEDIUMTEXT -> TEXT", "LONGTEXT -> TEXT", "CLOB -> TEXT", "LONG VARCHAR -> TEXT", "JSON -> TEXT", "JSONB -> TEXT",
+		"BYTEA -> BLOB", "BYTES -> BLOB", "LONGBLOB -> BLOB", "VARBINARY -> BLOB", "LONG VARBINARY -> BLOB", "RAW -> BLOB",
+		"DATETIME -> TIMESTAMP", "TIMESTAMP -> TIMESTAMP", "TIMESTAMP WITH TIME ZONE -> TIMESTAMP", "TIMESTAMPTZ -> TIMESTAMP",
+	},
+}
+
+// integerTypes represents target integer type used for NUMERIC identifier columns
+var integerTypes = map[string]string{
+	"mysql": "INT", "postgres": "INTEGER", "sqlite": "INTEGER", "oracle": "NUMBER(10)", "vertica": "INT", "bigquery": "INT64", "cassandra": "INT",
+}
+
+// typeMappingRegistry represents registered and URL loaded type mapping rules
+type typeMappingRegistry struct {
+	mux    sync.RWMutex
+	rules  map[string][]*TypeMappingRule
+	loaded map[string][]*TypeMappingRule
+}
+
+var typeMappings = &typeMappingRegistry{loaded: make(map[string][]*TypeMappingRule)}
+
+func (r *typeMappingRegistry) loadDefaults() {
+	r.rules = make(map[string][]*TypeMappingRule)
+	for vendor, expressions := range defaultTypeMappings {
+		var rules = make([]*TypeMappingRule, 0, len(expressions)+1)
+		if integerType, ok := integerTypes[vendor]; ok {
+			rule := &TypeMappingRule{Source: "NUMERIC", Target: integerType, Column: "*id"}
+			_ = rule.Init()
+			rules = append(rules, rule)
+		}
+		for _, expression := range expressions {
+			rule, err := NewTypeMappingRule(expression)
+			if err != nil {
+				panic(err)
+			}
+			rules = append(rules, rule)
+		}
+		r.rules[vendor] = rules
+	}
+}
+
+// register adds rules taking precedence over already registered ones
+func (r *typeMappingRegistry) register(target string, rules []*TypeMappingRule) error {
+	for _, rule := range rules {
+		if err := rule.Init(); err != nil {
+			return err
+		}
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.rules == nil {
+		r.loadDefaults()
+	}
+	vendor := typeVendor(target)
+	r.rules[vendor] = append(append([]*TypeMappingRule{}, rules...), r.rules[vendor]...)
+	return nil
+}
+
+// get returns target rules, rules loaded from mapping URL take precedence
+func (r *typeMappingRegistry) get(target, mappingURL string) ([]*TypeMappingRule, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.rules == nil {
+		r.loadDefaults()
+	}
+	var result = make([]*TypeMappingRule, 0)
+	if mappingURL != "" {
+		location := url.Normalize(mappingURL, file.Scheme)
+		rules, ok := r.loaded[location]
+		if !ok {
+			var err error
+			if rules, err = loadTypeMappingRules(location); err != nil {
+				return nil, err
+			}
+			r.loaded[location] = rules
+		}
+		result = append(result, rules...)
+	}
+	return append(result, r.rules[typeVendor(target)]...), nil
+}
+
+// loadTypeMappingRules loads rules either as a list of TypeMappingRule or a map of source to target type patterns
+func loadTypeMappingRules(URL string) ([]*TypeMappingRule, error) {
+	var data interface{}
+	if err := dsurl.Decode(URL, &data); err != nil {
+		return nil, err
+	}
+	var result = make([]*TypeMappingRule, 0)
+	if toolbox.IsMap(data) {
+		for source, target := range toolbox.AsMap(data) {
+			result = append(result, &TypeMappingRule{Source: source, Target: toolbox.AsString(target)})
+		}
+		//parametrized patterns go first, so that VARCHAR(n) takes precedence over VARCHAR
+		sort.SliceStable(result, func(i, j int) bool {
+			_, params1 := parseType(result[i].Source)
+			_, params2 := parseType(result[j].Source)
+			if len(params1) != len(params2) {
+				return len(params1) > len(params2)
+			}
+			return result[i].Source < result[j].Source
+		})
+	} else if err := toolbox.DefaultConverter.AssignConverted(&result, data); err != nil {
+		return nil, fmt.Errorf("failed to load type mapping %v, %v", URL, err)
+	}
+	for _, rule := range result {
+		if err := rule.Init(); err != nil {
+			return nil, fmt.Errorf("failed to load type mapping %v, %v", URL, err)
+		}
+	}
+	return result, nil
+}
+
+// RegisterTypeMapping registers target vendor type mapping rules, registered rules take precedence over default ones
+func RegisterTypeMapping(target string, rules ...*TypeMappingRule) error {
+	return typeMappings.register(target, rules)
+}
+
+// typeMapper maps source column types to target vendor types
+type typeMapper struct {
+	sourceVendor string
+	rules        []*TypeMappingRule
+}
+
+// Map returns target type for supplied column type, unmatched types are returned as is
+func (m *typeMapper) Map(column, dbType string) string {
+	if m == nil {
+		return dbType
+	}
+	for _, rule := range m.rules {
+		if mapped, ok := rule.Map(m.sourceVendor, column, dbType); ok {
+			return mapped
+		}
+	}
+	return dbType
+}
+
+// getTypeMapper returns source to target type mapper, nil if target is empty
+func getTypeMapper(source, target, mappingURL string) (*typeMapper, error) {
+	if target == "" {
+		return nil, nil
+	}
+	rules, err := typeMappings.get(target, mappingURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("unsupported target: %v, consider adding mapping URL", target)
+	}
+	return &typeMapper{sourceVendor: typeVendor(source), rules: rules}, nil
+}
+
+// expandType replaces target type placeholders with captured parameters, parameters are dropped if any placeholder was not captured
+func expandType(target string, captured map[string]string) string {
+	matched := typeExpr.FindStringSubmatch(strings.TrimSpace(target))
+	if len(matched) == 0 || matched[2] == "" {
+		return strings.ToUpper(strings.TrimSpace(target))
+	}
+	var params = make([]string, 0)
+	for _, param := range strings.Split(matched[3], ",") {
+		param = strings.TrimSpace(param)
+		if placeholderExpr.MatchString(param) {
+			value, ok := captured[param]
+			if !ok {
+				return formatType(matched[1], nil, matched[4])
+			}
+			param = value
+		}
+		params = append(params, param)
+	}
+	return formatType(matched[1], params, matched[4])
+}
+
+// unparametrizedTypes represents types which parameters are display width or storage hints
+var unparametrizedTypes = map[string]bool{
+	"INT": true, "INTEGER": true, "SMALLINT": true, "MEDIUMINT": true, "BIGINT": true, "INT64": true,
+	"TEXT": true, "TINYTEXT": true, "MEDIUMTEXT": true, "LONGTEXT": true, "CLOB": true,
+	"BLOB": true, "TINYBLOB": true, "MEDIUMBLOB": true, "LONGBLOB": true, "BYTEA": true,
+	"DATE": true, "BOOLEAN": true, "BOOL": true, "JSON": true, "JSONB": true, "FLOAT64": true,
+}
+
+// formatType returns upper case type with parameters i.e. TIMESTAMP(6) WITH TIME ZONE
+func formatType(name string, params []string, modifiers string) string {
+	result := strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	if len(params) > 0 {
+		result += "(" + strings.ToUpper(strings.Join(params, ",")) + ")"
+	}
+	if modifiers = strings.Join(strings.Fields(modifiers), " "); modifiers != "" {
+		result += " " + strings.ToUpper(modifiers)
+	}
+	return result
+}
+
+// normalizeType returns upper case type with trimmed parameters, integer display width and storage hints are removed i.e. int(11) unsigned -> INT UNSIGNED
+func normalizeType(dbType string) string {
+	matched := typeExpr.FindStringSubmatch(strings.TrimSpace(dbType))
+	if len(matched) == 0 {
+		return strings.ToUpper(strings.TrimSpace(dbType))
+	}
+	var params []string
+	if matched[2] != "" && !unparametrizedTypes[strings.ToUpper(strings.TrimSpace(matched[1]))] {
+		for _, param := range strings.Split(matched[3], ",") {
+			params = append(params, strings.TrimSpace(param))
+		}
+	}
+	return formatType(matched[1], params, matched[4])
+}
+
+var decimalTypes = map[string]bool{"DECIMAL": true, "NUMERIC": true, "NUMBER": true}
+
+var lengthTypes = map[string]bool{
+	"CHAR": true, "CHARACTER": true, "NCHAR": true, "VARCHAR": true, "NVARCHAR": true, "VARCHAR2": true, "NVARCHAR2": true, "CHARACTER VARYING": true,
+	"BINARY": true, "VARBINARY": true, "RAW": true, "BIT": true, "TINYINT": true, "STRING": true, "BYTES": true,
+}
+
+// columnType returns parametrized column type, length, precision and scale are taken from column metadata if not declared in type name
+func columnType(column dsc.Column) string {
+	dbType := strings.ToUpper(strings.TrimSpace(column.DatabaseTypeName()))
+	if !strings.Contains(dbType, "(") {
+		if precision, scale, ok := column.DecimalSize(); ok && precision > 0 && decimalTypes[dbType] {
+			dbType = fmt.Sprintf("%v(%v,%v)", dbType, precision, scale)
+		} else if length, ok := column.Length(); ok && length > 0 && length < math.MaxInt32 && lengthTypes[dbType] {
+			dbType = fmt.Sprintf("%v(%v)", dbType, length)
+		}
+	}
+	return normalizeType(dbType)
+}
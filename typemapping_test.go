@@ -0,0 +1,110 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestNormalizeType(t *testing.T) {
+	var useCases = map[string]string{
+		"int(11) unsigned":            "INT UNSIGNED",
+		"DECIMAL(7, 2)":               "DECIMAL(7,2)",
+		"varchar(255)":                "VARCHAR(255)",
+		"character varying(32)":       "CHARACTER VARYING(32)",
+		"timestamp(6) with time zone": "TIMESTAMP(6) WITH TIME ZONE",
+		"TINYINT(1)":                  "TINYINT(1)",
+		"longtext":                    "LONGTEXT",
+		"DOUBLE  PRECISION":           "DOUBLE PRECISION",
+	}
+	for dbType, expect := range useCases {
+		assert.Equal(t, expect, normalizeType(dbType), dbType)
+	}
+}
+
+func TestTypeMapper_Map(t *testing.T) {
+	var useCases = []struct {
+		source string
+		target string
+		column string
+		dbType string
+		expect string
+	}{
+		{"mysql", "bigquery", "name", "VARCHAR(64)", "STRING(64)"},
+		{"mysql", "bigquery", "price", "DECIMAL(7,2)", "NUMERIC(7,2)"},
+		{"mysql", "bigquery", "active", "TINYINT(1)", "BOOL"},
+		{"mysql", "bigquery", "level", "TINYINT(4)", "INT64"},
+		{"mysql", "postgres", "level", "TINYINT(4)", "SMALLINT"},
+		{"mysql", "postgres", "created", "DATETIME(6)", "TIMESTAMP(6)"},
+		{"postgres", "mysql", "created", "TIMESTAMP(3) WITH TIME ZONE", "TIMESTAMP(3)"},
+		{"postgres", "mysql", "status", "CHARACTER VARYING(32)", "VARCHAR(32)"},
+		{"postgres", "mysql", "amount", "NUMERIC", "DECIMAL(38,9)"},
+		{"pgx", "oracle", "created", "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE"},
+		{"sqlite3", "mysql", "user_id", "NUMERIC", "INT"},
+		{"mysql", "sqlite3", "id", "BIGINT", "INTEGER"},
+		{"oracle", "postgres", "amount", "NUMBER(10,2)", "NUMERIC(10,2)"},
+		{"mysql", "oracle", "name", "VARCHAR(64)", "VARCHAR2(64)"},
+		{"mysql", "vertica", "created", "TIMESTAMP(6) WITH TIME ZONE", "TIMESTAMPTZ(6)"},
+		{"mysql", "cassandra", "name", "VARCHAR(64)", "TEXT"},
+		{"cassandra", "mysql", "event_id", "TIMEUUID", "CHAR(36)"},
+		{"mysql", "postgres", "payload", "GEOMETRY", "GEOMETRY"},
+	}
+	for _, useCase := range useCases {
+		mapper, err := getTypeMapper(useCase.source, useCase.target, "")
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, useCase.expect, mapper.Map(useCase.column, useCase.dbType), useCase.source+" -> "+useCase.target+": "+useCase.dbType)
+	}
+	mapper, err := getTypeMapper("mysql", "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "VARCHAR(64)", mapper.Map("name", "VARCHAR(64)"))
+	_, err = getTypeMapper("mysql", "unknown", "")
+	assert.NotNil(t, err)
+}
+
+func TestTypeMapper_MappingURL(t *testing.T) {
+	parent := path.Join(os.TempDir(), "dsunit_type_mapping")
+	_ = os.MkdirAll(parent, 0744)
+	defer os.RemoveAll(parent)
+	mapURL := path.Join(parent, "map.json")
+	_ = ioutil.WriteFile(mapURL, []byte(`{"VARCHAR": "STRING", "VARCHAR(n)": "STRING(n)", "CUSTOM": "INT64"}`), 0644)
+	rulesURL := path.Join(parent, "rules.json")
+	_ = ioutil.WriteFile(rulesURL, []byte(`[{"Source": "DECIMAL(p,s)", "Target": "BIGNUMERIC(p,s)", "SourceVendor": "mysql"}, {"Source": "INT", "Target": "STRING", "Column": "*_code"}]`), 0644)
+
+	mapper, err := getTypeMapper("mysql", "bigquery", mapURL)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "STRING(20)", mapper.Map("name", "VARCHAR(20)"))
+		assert.Equal(t, "STRING", mapper.Map("name", "VARCHAR"))
+		assert.Equal(t, "INT64", mapper.Map("flag", "CUSTOM"))
+		assert.Equal(t, "NUMERIC(7,2)", mapper.Map("price", "DECIMAL(7,2)"))
+	}
+	mapper, err = getTypeMapper("mysql", "bigquery", rulesURL)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "BIGNUMERIC(7,2)", mapper.Map("price", "DECIMAL(7,2)"))
+		assert.Equal(t, "STRING", mapper.Map("zip_code", "INT"))
+		assert.Equal(t, "INT64", mapper.Map("id", "INT"))
+	}
+	mapper, err = getTypeMapper("postgres", "bigquery", rulesURL)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "NUMERIC(7,2)", mapper.Map("price", "DECIMAL(7,2)"))
+	}
+	_, err = getTypeMapper("mysql", "bigquery", path.Join(parent, "missing.json"))
+	assert.NotNil(t, err)
+}
+
+func TestRegisterTypeMapping(t *testing.T) {
+	rule, err := NewTypeMappingRule("VARCHAR(n) -> TEXT(n)")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, RegisterTypeMapping("testdb", rule))
+	mapper, err := getTypeMapper("mysql", "testdb", "")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "TEXT(10)", mapper.Map("name", "VARCHAR(10)"))
+	}
+	_, err = NewTypeMappingRule("VARCHAR")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,113 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/toolbox"
+	"regexp"
+	"strings"
+)
+
+// ExistsPredicate represents assertly key existence predicate
+const ExistsPredicate = "@exists@"
+
+var timestampColumnExpr = regexp.MustCompile(`(^|_)(created|updated|modified|inserted|deleted|changed|last_?(access|login|seen|modified|update|updated))(_?(at|on|time|ts|date|timestamp))?$|_(at|ts)$|(^|_)timestamp$`)
+var tokenColumnExpr = regexp.MustCompile(`(^|_)(token|secret|nonce|session(_?id)?|signature|salt|hash|checksum|digest|etag|uuid|guid)($|_)`)
+var uuidExpr = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+var hexExpr = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+var tokenExpr = regexp.MustCompile(`^[A-Za-z0-9_\-+/=.]{24,}$`)
+
+const uuidPredicate = `~/^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$/`
+
+// volatileColumnName returns lower underscore column name
+func volatileColumnName(column string) string {
+	if strings.ToUpper(column) == column {
+		return strings.ToLower(column)
+	}
+	return convertToLowerUnderscore(column)
+}
+
+// detectVolatileColumns returns predicates for columns with values changing between runs (timestamps, UUIDs, hashes and tokens),
+// detection is based on value type, column name pattern and user supplied volatile columns.
+func detectVolatileColumns(records []map[string]interface{}, request *FreezeRequest) map[string]string {
+	var values = make(map[string][]interface{})
+	for _, record := range records {
+		for column, value := range record {
+			if value == nil || strings.HasPrefix(column, "@") {
+				continue
+			}
+			values[column] = append(values[column], value)
+		}
+	}
+	var volatile = make(map[string]bool)
+	for _, column := range request.Volatile {
+		volatile[column] = true
+	}
+	var result = make(map[string]string)
+	for column, columnValues := range values {
+		name := volatileColumnName(column)
+		if predicate := volatilePredicate(name, columnValues, volatile[column], request); predicate != "" {
+			result[column] = predicate
+		} else if volatile[column] {
+			result[column] = ExistsPredicate
+		}
+	}
+	return result
+}
+
+func volatilePredicate(name string, values []interface{}, forced bool, request *FreezeRequest) string {
+	var texts = make([]string, 0, len(values))
+	isTime := true
+	for _, value := range values {
+		if !toolbox.IsTime(value) {
+			isTime = false
+		}
+		texts = append(texts, toolbox.AsString(value))
+	}
+	if isTime {
+		if !forced && !timestampColumnExpr.MatchString(name) {
+			return ""
+		}
+		if request.TimeWindowSec > 0 {
+			if request.TimeFormat != "" {
+				return fmt.Sprintf(`<ds:within_sec["now", %v, "%v"]>`, request.TimeWindowSec, request.TimeFormat)
+			}
+			return fmt.Sprintf(`<ds:within_sec["now", %v]>`, request.TimeWindowSec)
+		}
+		return ExistsPredicate
+	}
+	if matchAll(texts, uuidExpr.MatchString) {
+		return uuidPredicate
+	}
+	if size := len(texts[0]); (size == 32 || size == 40 || size == 64) && matchAll(texts, func(text string) bool {
+		return len(text) == size && hexExpr.MatchString(text)
+	}) {
+		return fmt.Sprintf(`~/^[0-9a-fA-F]{%v}$/`, size)
+	}
+	if tokenColumnExpr.MatchString(name) && matchAll(texts, tokenExpr.MatchString) {
+		return ExistsPredicate
+	}
+	return ""
+}
+
+func matchAll(texts []string, matcher func(text string) bool) bool {
+	for _, text := range texts {
+		if !matcher(text) {
+			return false
+		}
+	}
+	return len(texts) > 0
+}
+
+// replaceVolatileValues replaces non empty volatile column values with predicates
+func replaceVolatileValues(records []map[string]interface{}, predicates map[string]string) {
+	if len(predicates) == 0 {
+		return
+	}
+	for _, record := range records {
+		for column, predicate := range predicates {
+			if value, ok := record[column]; ok && value != nil {
+				record[column] = predicate
+			}
+		}
+	}
+}
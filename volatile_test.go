@@ -0,0 +1,36 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDetectVolatileColumns(t *testing.T) {
+	now := time.Now()
+	records := []map[string]interface{}{
+		{"id": 1, "name": "abc", "created_at": now, "birth_date": now, "uuid": "0f8fad5b-d9cb-469f-a165-70867728950e", "hash": "5d41402abc4b2a76b9719d911017c592", "accessToken": "eyJhbGciOiJIUzI1NiJ9.e30.ZRrHA1JJJW8opsbCGfG_HACGpVUMN_a9IV7pAx_Zmeo", "build": "x1"},
+		{"id": 2, "name": "xyz", "created_at": now, "birth_date": nil, "uuid": "7c9e6679-7425-40de-944b-e07fc1f90ae7", "hash": "7d793037a0760186574b0282f2f435e7", "accessToken": nil, "build": "x2"},
+	}
+	{
+		predicates := detectVolatileColumns(records, &FreezeRequest{Volatile: []string{"build"}})
+		assert.EqualValues(t, map[string]string{
+			"created_at":  ExistsPredicate,
+			"uuid":        uuidPredicate,
+			"hash":        "~/^[0-9a-fA-F]{32}$/",
+			"accessToken": ExistsPredicate,
+			"build":       ExistsPredicate,
+		}, predicates)
+
+		replaceVolatileValues(records, predicates)
+		assert.EqualValues(t, ExistsPredicate, records[0]["created_at"])
+		assert.EqualValues(t, now, records[0]["birth_date"])
+		assert.Nil(t, records[1]["accessToken"])
+	}
+	{
+		predicates := detectVolatileColumns([]map[string]interface{}{{"updatedAt": now}}, &FreezeRequest{TimeWindowSec: 60, TimeFormat: "yyyy-MM-dd HH:mm:ss"})
+		assert.EqualValues(t, map[string]string{
+			"updatedAt": `<ds:within_sec["now", 60, "yyyy-MM-dd HH:mm:ss"]>`,
+		}, predicates)
+	}
+}